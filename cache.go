@@ -1,227 +1,665 @@
 package redisTool
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
 	"math/rand"
 	"reflect"
+	"sync/atomic"
 	"time"
 
 	"github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
 )
 
+// cacheLoadPollInterval 等待其他进程加载缓存时的轮询间隔
+const cacheLoadPollInterval = time.Millisecond * 50
+
+// cacheKeysBatch Keys 排除“不存在”标记时每批读取的条目数
+const cacheKeysBatch = 1000
+
+// ErrNotFound 数据不存在，GetOrLoad 的加载函数返回它表示可以缓存“不存在”
+var ErrNotFound = errors.New("redisTool: not found")
+
+// cacheNotFoundMarker 缓存中表示“不存在”的标记值
+var cacheNotFoundMarker = []byte("\x00redisTool:not-found\x00")
+
 // Cache 缓存
 type Cache[T any] struct {
-	redis      *Redis
-	dataName   string
-	expireName string
-	config     CacheConfig
+	redis    *Redis
+	name     string
+	baseName string
+	store    cacheStore
+	flight   *flightGroup[cacheResult[T]]
+	loader   func(key string) (T, time.Duration)
+	loadCost *atomic.Int64 // 加载耗时的滑动平均（纳秒），用于提前刷新
+	counters *cacheCounters
+	config   CacheConfig
+
+	stopStats context.CancelFunc // SharedStats 时停止后台写入统计增量
+
+	// 本地缓存，LocalSize 大于 0 时启用
+	local        *localCache[T]
+	invalidation *Topic[cacheInvalidation]
+	instanceID   string
+	closeLocal   context.CancelFunc
+	touches      *cacheTouches // 本地命中待写入的访问记录，同时配置了 MaxEntries 时使用
 }
 
+// cacheResult 加载结果
+type cacheResult[T any] struct {
+	value T
+	err   error
+}
+
+// cacheInvalidation 本地缓存失效通知
+type cacheInvalidation struct {
+	Source string   // 发出通知的缓存实例，实例忽略自己发出的通知
+	Keys   []string // 失效的键
+	All    bool     // 是否清空全部
+}
+
+// WithContext 返回绑定了上下文的缓存视图
+func (c *Cache[T]) WithContext(ctx context.Context) *Cache[T] {
+	cc := *c
+	cc.redis = c.redis.WithContext(ctx)
+	cc.store = c.store.withRedis(cc.redis)
+	return &cc
+}
+
+// SetLoader 设置加载函数，应在使用缓存之前设置
+// 设置后 Get 读到已过软过期时间（或被提前刷新选中）的条目时，仍返回旧值并在后台调用 loader 刷新
+func (c *Cache[T]) SetLoader(loader func(key string) (T, time.Duration)) {
+	c.loader = loader
+}
 
 // Set 设置缓存
+// 配置了 StaleTime 时，条目在 expire 后进入过期可用期，再经过 StaleTime 才真正删除
 func (c *Cache[T]) Set(key string, value T, expire time.Duration) error {
 	data, err := c.redis.Serialize(value)
 	if err != nil {
 		return err
 	}
 	
-	conn := c.redis.GetConn()
-	defer conn.Close()
-	
-	// 设置数据
-	if _, err := conn.Do("HSET", c.dataName, key, data); err != nil {
+	if expire <= 0 {
+		expire = c.config.DefaultExpire
+	}
+	if expire > 0 {
+		expire += c.config.StaleTime
+	}
+	if err := c.write(map[string][]byte{key: data}, expire); err != nil {
 		return err
 	}
+	c.record(cacheStatSets, 1)
 	
-	// 设置过期时间
-	if expire > 0 {
-		expireTime := float64(time.Now().Add(expire).UnixMilli())
-		if _, err := conn.Do("ZADD", c.expireName, expireTime, key); err != nil {
-			return err
-		}
-	} else if c.config.DefaultExpire > 0 {
-		expireTime := float64(time.Now().Add(c.config.DefaultExpire).UnixMilli())
-		if _, err := conn.Do("ZADD", c.expireName, expireTime, key); err != nil {
+	c.maybeCleanup()
+	return c.invalidate(false, key)
+}
+
+// SetMany 批量设置缓存，所有条目使用相同的过期时间，只需一次往返
+func (c *Cache[T]) SetMany(values map[string]T, expire time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+	
+	entries := make(map[string][]byte, len(values))
+	keys := make([]string, 0, len(values))
+	for key, value := range values {
+		data, err := c.redis.Serialize(value)
+		if err != nil {
 			return err
 		}
+		entries[key] = data
+		keys = append(keys, key)
+	}
+	
+	if expire <= 0 {
+		expire = c.config.DefaultExpire
+	}
+	if expire > 0 {
+		expire += c.config.StaleTime
 	}
+	if err := c.write(entries, expire); err != nil {
+		return err
+	}
+	c.record(cacheStatSets, int64(len(entries)))
 	
-	// 概率性清理过期数据（10% 概率）
+	c.maybeCleanup()
+	return c.invalidate(false, keys...)
+}
+
+// maybeCleanup 概率性清理过期数据（10% 概率）
+func (c *Cache[T]) maybeCleanup() {
 	if rand.Intn(10) == 0 {
 		// 使用 AcrossMinute 判断是否横跨分钟
-		cleanupKey := c.dataName + ":cleanup"
-		if c.redis.AcrossMinute(cleanupKey) {
+		if c.redis.AcrossMinute(c.baseName + ":data:cleanup") {
 			go c.ClearExpired()
 		}
 	}
-	
-	return nil
 }
 
 // Get 获取缓存
 func (c *Cache[T]) Get(key string) (T, bool) {
+	value, ttl, ok := c.lookup(key)
+	if ok && c.loader != nil && c.shouldRefresh(ttl) {
+		c.refreshAsync(key, wrapCacheFactory(c.loader))
+	}
+	return value, ok
+}
+
+// GetMany 批量获取缓存，只返回存在且未过期的条目
+// 过期检查和读取在一次往返中完成；启用本地缓存时先查本地缓存
+func (c *Cache[T]) GetMany(keys ...string) map[string]T {
+	result := make(map[string]T, len(keys))
+	
+	missing := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if c.local != nil {
+			if value, ok := c.local.get(key); ok {
+				result[key] = value
+				c.touchLocal(key)
+				continue
+			}
+		}
+		missing = append(missing, key)
+	}
+	if len(missing) == 0 {
+		c.record(cacheStatHits, int64(len(result)))
+		return result
+	}
+	
 	var zero T
+	hits := make([]string, 0, len(missing))
+	defer func() {
+		c.touch(hits...)
+		c.record(cacheStatHits, int64(len(result)))
+		c.record(cacheStatMisses, int64(len(keys)-len(result)))
+	}()
 	
-	// 检查是否过期
-	if c.isExpired(key) {
-		c.Delete(key)
-		return zero, false
+	dataList, ttls, err := c.store.getMany(missing)
+	if err != nil {
+		return result
 	}
 	
-	data, err := redis.Bytes(c.redis.Do("HGET", c.dataName, key))
-	if err != nil || len(data) == 0 {
-		return zero, false
+	for i, key := range missing {
+		data := dataList[i]
+		if len(data) == 0 || bytes.Equal(data, cacheNotFoundMarker) {
+			continue
+		}
+		
+		ptr := reflect.New(reflect.TypeOf(zero)).Interface()
+		if err := c.redis.Deserialize(data, ptr); err != nil {
+			continue
+		}
+		value := reflect.ValueOf(ptr).Elem().Interface().(T)
+		result[key] = value
+		hits = append(hits, key)
+		
+		c.fillLocal(key, value, ttls[i])
+		if c.loader != nil && c.shouldRefresh(ttls[i]) {
+			c.refreshAsync(key, wrapCacheFactory(c.loader))
+		}
+	}
+	return result
+}
+
+// get 读取缓存并返回剩余的真实生存时间（没有过期时间时为 -1），“不存在”标记视为未命中
+func (c *Cache[T]) get(key string) (T, time.Duration, bool) {
+	value, ttl, err := c.fetch(key)
+	return value, ttl, err == nil
+}
+
+// fetch 读取缓存，未命中时返回错误，命中“不存在”标记时返回 ErrNotFound
+func (c *Cache[T]) fetch(key string) (T, time.Duration, error) {
+	var zero T
+	
+	data, ttl, err := c.store.get(key)
+	if err != nil {
+		return zero, 0, err
+	}
+	if len(data) == 0 {
+		return zero, 0, redis.ErrNil
+	}
+	if bytes.Equal(data, cacheNotFoundMarker) {
+		return zero, ttl, ErrNotFound
 	}
 	
 	result := reflect.New(reflect.TypeOf(zero)).Interface()
 	if err := c.redis.Deserialize(data, result); err != nil {
-		return zero, false
+		return zero, 0, err
 	}
 	
-	return reflect.ValueOf(result).Elem().Interface().(T), true
+	return reflect.ValueOf(result).Elem().Interface().(T), ttl, nil
+}
+
+// lookup 先查本地缓存，未命中时读取 Redis 并写入本地缓存
+// 本地命中时 ttl 返回 -1，不触发刷新；已进入过期可用期的条目不写入本地缓存
+func (c *Cache[T]) lookup(key string) (T, time.Duration, bool) {
+	if c.local != nil {
+		if value, ok := c.local.get(key); ok {
+			c.record(cacheStatHits, 1)
+			c.touchLocal(key)
+			return value, -1, true
+		}
+	}
+	
+	value, ttl, ok := c.get(key)
+	if ok {
+		c.record(cacheStatHits, 1)
+		c.touch(key)
+		c.fillLocal(key, value, ttl)
+	} else {
+		c.record(cacheStatMisses, 1)
+	}
+	return value, ttl, ok
+}
+
+// fillLocal 把从 Redis 读到的条目写入本地缓存，已进入过期可用期的条目不写入
+func (c *Cache[T]) fillLocal(key string, value T, ttl time.Duration) {
+	if c.local == nil {
+		return
+	}
+	if ttl < 0 {
+		c.local.set(key, value, 0)
+	} else if fresh := ttl - c.config.StaleTime; fresh > 0 {
+		c.local.set(key, value, fresh)
+	}
 }
 
 // GetOrSet 获取或设置缓存
+// 缓存未命中时，同一进程内的并发调用只执行一次 factory；
+// 多个进程之间通过一个短时的 Redis 锁保证只有一个调用者执行 factory，其他调用者最多等待 LoadWaitTime，超时后自行执行；
+// 命中已过软过期时间的条目时返回旧值，并在后台用 factory 刷新
 func (c *Cache[T]) GetOrSet(key string, factory func(key string) (T, time.Duration)) T {
-	value, ok := c.Get(key)
+	value, _ := c.GetOrLoad(key, wrapCacheFactory(factory))
+	return value
+}
+
+// GetOrLoad 获取缓存，未命中时调用 loader 加载
+// loader 返回错误时不写入缓存，并把错误返回给调用者；
+// loader 返回 ErrNotFound（可以包装）且配置了 NegativeExpire 时，缓存“不存在”标记，在此期间直接返回 ErrNotFound；
+// 与 GetOrSet 一样支持单飞去重、跨进程加载锁和过期可用期内的后台刷新，后台刷新失败时保留旧值
+func (c *Cache[T]) GetOrLoad(key string, loader func(key string) (T, time.Duration, error)) (T, error) {
+	value, ttl, ok := c.lookup(key)
 	if ok {
-		return value
+		if c.shouldRefresh(ttl) {
+			c.refreshAsync(key, loader)
+		}
+		return value, nil
 	}
 	
-	value, expire := factory(key)
-	c.Set(key, value, expire)
-	return value
+	result := c.flight.do(key, func() cacheResult[T] {
+		return c.load(key, loader)
+	})
+	return result.value, result.err
 }
 
-// Delete 删除缓存
-func (c *Cache[T]) Delete(keys ...string) error {
-	if len(keys) == 0 {
-		return nil
+// load 获取加载锁后执行 loader 并写入缓存，未获得锁时等待持有锁的调用者写入结果
+func (c *Cache[T]) load(key string, loader func(key string) (T, time.Duration, error)) cacheResult[T] {
+	// 在等待单飞结果期间可能已经有其他调用者写入
+	if result, ok := c.loaded(key); ok {
+		return result
 	}
 	
-	conn := c.redis.GetConn()
-	defer conn.Close()
-	
-	// 删除数据
-	dataArgs := make([]interface{}, 0, len(keys)+1)
-	dataArgs = append(dataArgs, c.dataName)
-	for _, key := range keys {
-		dataArgs = append(dataArgs, key)
+	if c.config.LoadLockTime > 0 {
+		// 获取锁时 Redis 出错不等待，直接加载
+		lock := c.loadLock(key)
+		acquired, err := lock.tryLock()
+		if acquired {
+			defer lock.Unlock()
+			
+			// 获得锁之前其他进程可能刚刚完成加载
+			if result, ok := c.loaded(key); ok {
+				return result
+			}
+		} else if err == nil {
+			if result, ok := c.waitLoaded(key, lock); ok {
+				return result
+			}
+		}
 	}
-	if _, err := conn.Do("HDEL", dataArgs...); err != nil {
-		return err
+	
+	return c.loadValue(key, loader)
+}
+
+// loaded 检查缓存中是否已有结果，包括“不存在”标记
+func (c *Cache[T]) loaded(key string) (cacheResult[T], bool) {
+	value, _, err := c.fetch(key)
+	if err == nil || err == ErrNotFound {
+		return cacheResult[T]{value: value, err: err}, true
 	}
+	return cacheResult[T]{}, false
+}
+
+// loadValue 调用加载函数并写入缓存，同时记录加载耗时
+func (c *Cache[T]) loadValue(key string, loader func(key string) (T, time.Duration, error)) cacheResult[T] {
+	start := time.Now()
+	value, expire, err := loader(key)
+	elapsed := time.Since(start)
+	c.recordLoadCost(elapsed)
+	c.recordLoad(elapsed, err)
 	
-	// 删除过期时间
-	expireArgs := make([]interface{}, 0, len(keys)+1)
-	expireArgs = append(expireArgs, c.expireName)
-	for _, key := range keys {
-		expireArgs = append(expireArgs, key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) && c.config.NegativeExpire > 0 {
+			c.setNotFound(key)
+		}
+		var zero T
+		return cacheResult[T]{value: zero, err: err}
 	}
-	if _, err := conn.Do("ZREM", expireArgs...); err != nil {
+	
+	c.Set(key, value, expire)
+	return cacheResult[T]{value: value}
+}
+
+// setNotFound 写入“不存在”标记
+func (c *Cache[T]) setNotFound(key string) error {
+	if err := c.write(map[string][]byte{key: cacheNotFoundMarker}, c.config.NegativeExpire); err != nil {
 		return err
 	}
+	return c.invalidate(false, key)
+}
+
+// refreshAsync 在后台刷新条目，同一个键同时只有一个刷新或加载在执行
+func (c *Cache[T]) refreshAsync(key string, loader func(key string) (T, time.Duration, error)) {
+	// 后台刷新不受调用者上下文的影响
+	bg := c.WithContext(context.Background())
 	
-	return nil
+	c.flight.doAsync(key, func() cacheResult[T] {
+		if bg.config.LoadLockTime > 0 {
+			// 其他进程正在刷新时跳过
+			lock := bg.loadLock(key)
+			if !lock.TryLock() {
+				if result, ok := bg.loaded(key); ok {
+					return result
+				}
+				return bg.load(key, loader)
+			}
+			defer lock.Unlock()
+		}
+		return bg.loadValue(key, loader)
+	})
 }
 
-// Exists 判断缓存是否存在
-func (c *Cache[T]) Exists(key string) bool {
-	if c.isExpired(key) {
-		c.Delete(key)
+// shouldRefresh 判断条目是否需要刷新
+// 剩余生存时间进入 StaleTime 窗口时需要刷新；配置了 EarlyRefreshBeta 时按 XFetch 算法在软过期之前随机提前刷新
+func (c *Cache[T]) shouldRefresh(ttl time.Duration) bool {
+	if ttl < 0 {
 		return false
 	}
 	
-	exists, err := redis.Int(c.redis.Do("HEXISTS", c.dataName, key))
-	if err != nil {
-		return false
+	fresh := ttl - c.config.StaleTime
+	if c.config.StaleTime > 0 && fresh <= 0 {
+		return true
+	}
+	
+	if c.config.EarlyRefreshBeta > 0 {
+		cost := float64(c.loadCost.Load())
+		if cost > 0 && -cost*c.config.EarlyRefreshBeta*math.Log(rand.Float64()) >= float64(fresh) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordLoadCost 更新加载耗时的滑动平均
+func (c *Cache[T]) recordLoadCost(d time.Duration) {
+	old := c.loadCost.Load()
+	if old == 0 {
+		c.loadCost.Store(int64(d))
+		return
+	}
+	c.loadCost.Store((old*7 + int64(d)) / 8)
+}
+
+// loadLock 创建跨进程加载锁，加载锁不需要防护令牌，避免为每个缓存键留下计数器
+func (c *Cache[T]) loadLock(key string) *Lock {
+	return c.redis.NewLock(c.name+":"+key, LockConfig{
+		WaitTime:           c.config.LoadLockTime,
+		MaxGetLockWaitTime: 0,
+		DisableFencing:     true,
+	})
+}
+
+// waitLoaded 等待持有加载锁的调用者写入缓存，锁被释放或等待超时时返回
+func (c *Cache[T]) waitLoaded(key string, lock *Lock) (cacheResult[T], bool) {
+	ctx := c.redis.Context()
+	deadline := time.Now().Add(c.config.LoadWaitTime)
+	
+	for time.Now().Before(deadline) {
+		timer := time.NewTimer(cacheLoadPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return cacheResult[T]{}, false
+		case <-timer.C:
+		}
+		
+		if result, ok := c.loaded(key); ok {
+			return result, true
+		}
+		if exists, err := redis.Int(c.redis.Do("EXISTS", lock.name)); err == nil && exists == 0 {
+			// 持有锁的调用者已放弃加载，再检查一次后自行加载
+			return c.loaded(key)
+		}
+	}
+	
+	return cacheResult[T]{}, false
+}
+
+// wrapCacheFactory 把不返回错误的 factory 转换为加载函数
+func wrapCacheFactory[T any](factory func(key string) (T, time.Duration)) func(key string) (T, time.Duration, error) {
+	return func(key string) (T, time.Duration, error) {
+		value, expire := factory(key)
+		return value, expire, nil
+	}
+}
+
+// Delete 删除缓存
+func (c *Cache[T]) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := c.store.delete(keys...); err != nil {
+		return err
+	}
+	c.record(cacheStatDeletes, int64(len(keys)))
+	if err := c.forget(keys...); err != nil {
+		return err
+	}
+	return c.invalidate(false, keys...)
+}
+
+// Exists 判断缓存是否存在，“不存在”标记不算存在
+func (c *Cache[T]) Exists(key string) bool {
+	if c.config.NegativeExpire > 0 {
+		_, _, err := c.fetch(key)
+		return err == nil
 	}
-	return exists == 1
+	return c.store.exists(key)
 }
 
 // Clear 清空缓存
 func (c *Cache[T]) Clear() error {
-	conn := c.redis.GetConn()
-	defer conn.Close()
-	
-	_, err := conn.Do("DEL", c.dataName, c.expireName)
-	return err
+	if err := c.store.clear(); err != nil {
+		return err
+	}
+	if err := c.clearMeta(); err != nil {
+		return err
+	}
+	return c.invalidate(true)
 }
 
-// ClearExpired 清理过期的缓存
+// ClearExpired 清理过期的缓存以及已失效条目的标签和访问记录
+// 使用 CacheStorageString 时条目由 Redis 自动过期，只需要清理标签和访问记录
 func (c *Cache[T]) ClearExpired() error {
-	now := float64(time.Now().UnixMilli())
-	
-	// 获取过期的键
-	keys, err := redis.Strings(c.redis.Do("ZRANGEBYSCORE", c.expireName, 0, now))
+	expired, err := c.store.clearExpired()
 	if err != nil {
 		return err
 	}
-	
-	if len(keys) > 0 {
-		return c.Delete(keys...)
+	c.record(cacheStatExpirations, int64(expired))
+	if err := c.sweep("HSCAN", c.tagsName()); err != nil {
+		return err
+	}
+	if c.config.MaxEntries > 0 {
+		return c.sweep("ZSCAN", c.accessName())
 	}
-	
 	return nil
 }
 
-// Length 获取缓存数量
+// Length 获取缓存数量，不包括“不存在”标记
+// 配置了 NegativeExpire 时需要读取所有条目以排除“不存在”标记
 func (c *Cache[T]) Length() int {
-	c.ClearExpired() // 清理过期缓存
+	if c.config.NegativeExpire <= 0 {
+		return c.store.length()
+	}
 	
-	length, err := redis.Int(c.redis.Do("HLEN", c.dataName))
+	keys, err := c.Keys()
 	if err != nil {
 		return 0
 	}
-	return length
+	return len(keys)
 }
 
-// Keys 获取所有键
+// Keys 获取所有键，不包括“不存在”标记
 func (c *Cache[T]) Keys() ([]string, error) {
-	c.ClearExpired() // 清理过期缓存
+	keys, err := c.store.keys()
+	if err != nil || c.config.NegativeExpire <= 0 {
+		return keys, err
+	}
 	
-	keys, err := redis.Strings(c.redis.Do("HKEYS", c.dataName))
-	if err != nil {
-		return nil, err
+	// 分批读取条目，排除“不存在”标记
+	result := make([]string, 0, len(keys))
+	for start := 0; start < len(keys); start += cacheKeysBatch {
+		batch := keys[start:min(start+cacheKeysBatch, len(keys))]
+		dataList, _, err := c.store.getMany(batch)
+		if err != nil {
+			return nil, err
+		}
+		for i, key := range batch {
+			if !bytes.Equal(dataList[i], cacheNotFoundMarker) {
+				result = append(result, key)
+			}
+		}
 	}
-	return keys, nil
+	return result, nil
 }
 
-// GetTTL 获取剩余生存时间
+// GetTTL 获取剩余生存时间，不包括 StaleTime，条目已进入过期可用期时返回 false
 func (c *Cache[T]) GetTTL(key string) (time.Duration, bool) {
-	score, err := redis.Float64(c.redis.Do("ZSCORE", c.expireName, key))
-	if err != nil {
+	ttl, ok := c.store.getTTL(key)
+	if !ok {
 		return 0, false
 	}
-	
-	expireTime := time.UnixMilli(int64(score))
-	ttl := time.Until(expireTime)
-	
+	ttl -= c.config.StaleTime
 	if ttl <= 0 {
 		return 0, false
 	}
-	
 	return ttl, true
 }
 
-// SetTTL 设置生存时间
+// SetTTL 设置生存时间，条目在 expire + StaleTime 后删除
 func (c *Cache[T]) SetTTL(key string, expire time.Duration) error {
-	if !c.Exists(key) {
-		return nil
+	if err := c.store.setTTL(key, expire+c.config.StaleTime); err != nil {
+		return err
 	}
-	
-	expireTime := float64(time.Now().Add(expire).UnixMilli())
-	_, err := c.redis.Do("ZADD", c.expireName, expireTime, key)
-	return err
+	return c.invalidate(false, key)
+}
+
+// Close 停止接收本地缓存失效通知并清空本地缓存，配置了 SharedStats 时停止后台写入并写入剩余的统计增量
+// 未启用本地缓存和 SharedStats 时无需调用
+func (c *Cache[T]) Close() error {
+	if c.stopStats != nil {
+		c.stopStats()
+	}
+	if c.local != nil {
+		c.closeLocal()
+		c.local.purge()
+		c.flushTouches()
+	}
+	return c.FlushStats()
 }
 
-// isExpired 判断是否过期
-func (c *Cache[T]) isExpired(key string) bool {
-	score, err := redis.Float64(c.redis.Do("ZSCORE", c.expireName, key))
+// startLocal 启用本地缓存并订阅失效通知，订阅失败时不启用本地缓存
+func (c *Cache[T]) startLocal() {
+	ctx, cancel := context.WithCancel(context.Background())
+	topic := NewTopic[cacheInvalidation](c.name+":invalidate", c.redis)
+	messages, err := topic.Subscribe(ctx)
 	if err != nil {
-		return false
+		cancel()
+		return
+	}
+	
+	local := newLocalCache[T](c.config.LocalSize, c.config.LocalExpire)
+	instanceID := uuid.New().String()
+	go func() {
+		for msg := range messages {
+			if msg.Source == instanceID {
+				continue
+			}
+			if msg.All {
+				local.purge()
+			} else {
+				local.remove(msg.Keys...)
+			}
+		}
+	}()
+	
+	c.local = local
+	c.invalidation = topic
+	c.instanceID = instanceID
+	c.closeLocal = cancel
+	
+	// 本地命中的访问记录定期批量写入，淘汰时热点条目不会因为只在本地被读取而先被淘汰
+	if c.config.MaxEntries > 0 {
+		c.touches = &cacheTouches{counts: make(map[string]int64)}
+		go func() {
+			ticker := time.NewTicker(cacheTouchFlushInterval)
+			defer ticker.Stop()
+			
+			for {
+				select {
+				case <-ticker.C:
+					c.flushTouches()
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
+
+// invalidate 删除本地缓存中的条目并通知其他实例
+func (c *Cache[T]) invalidate(all bool, keys ...string) error {
+	if c.local == nil {
+		return nil
+	}
+	
+	if all {
+		c.local.purge()
+	} else {
+		c.local.remove(keys...)
 	}
 	
-	expireTime := time.UnixMilli(int64(score))
-	return time.Now().After(expireTime)
+	_, err := c.invalidation.WithContext(c.redis.Context()).Publish(cacheInvalidation{
+		Source: c.instanceID,
+		Keys:   keys,
+		All:    all,
+	})
+	return err
 }
 
+// newCacheConfig 填充缓存配置的默认值
+func newCacheConfig(config CacheConfig) CacheConfig {
+	if config.LoadLockTime == 0 {
+		config.LoadLockTime = time.Second * 10
+	}
+	if config.LoadWaitTime == 0 {
+		config.LoadWaitTime = time.Second * 5
+	}
+	if config.StatsFlushInterval <= 0 {
+		config.StatsFlushInterval = cacheStatsFlushInterval
+	}
+	return config
+}
@@ -0,0 +1,195 @@
+package redisTool
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// CacheEvictionPolicy 缓存淘汰策略
+type CacheEvictionPolicy int
+
+const (
+	CacheEvictLRU CacheEvictionPolicy = iota // 淘汰最久未访问的条目（默认）
+	CacheEvictLFU                            // 淘汰访问次数最少的条目
+)
+
+// cacheTouchFlushInterval 本地缓存命中的访问记录写入 Redis 的间隔
+const cacheTouchFlushInterval = time.Second
+
+// cacheTouches 本地缓存命中后尚未写入访问记录的条目及其命中次数
+// 本地命中不访问 Redis，访问记录定期批量写入，避免热点条目在访问记录中显得最冷而被优先淘汰
+type cacheTouches struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// add 累加一次命中
+func (t *cacheTouches) add(key string) {
+	t.mu.Lock()
+	t.counts[key]++
+	t.mu.Unlock()
+}
+
+// take 取出并清空累积的命中次数
+func (t *cacheTouches) take() map[string]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counts := t.counts
+	t.counts = make(map[string]int64)
+	return counts
+}
+
+// write 写入条目；配置了 MaxEntries 时在同一个脚本中写入、记录访问并淘汰超出容量的条目
+func (c *Cache[T]) write(entries map[string][]byte, expire time.Duration) error {
+	if c.config.MaxEntries <= 0 {
+		if len(entries) == 1 {
+			for key, data := range entries {
+				return c.store.set(key, data, expire)
+			}
+		}
+		return c.store.setMany(entries, expire)
+	}
+
+	script := cacheEntryScript + `
+		local expire = tonumber(ARGV[5])
+		local now = tonumber(ARGV[8])
+		local written = {}
+		for i = 9, #ARGV, 2 do
+			local key, data = ARGV[i], ARGV[i + 1]
+			written[key] = true
+			if ARGV[1] == 'hash' then
+				redis.call('HSET', ARGV[2], key, data)
+				if expire > 0 then
+					redis.call('ZADD', ARGV[3], now + expire, key)
+				end
+			elseif expire > 0 then
+				redis.call('SET', ARGV[2] .. key, data, 'PX', expire)
+			else
+				redis.call('SET', ARGV[2] .. key, data)
+			end
+
+			if ARGV[7] == 'lfu' then
+				redis.call('ZINCRBY', KEYS[2], 1, key)
+			else
+				redis.call('ZADD', KEYS[2], now, key)
+			end
+		end
+
+		local evicted = {}
+		local function evict(key)
+			if entryExists(key) then
+				entryRemove(key)
+				evicted[#evicted + 1] = key
+			end
+			untag(key)
+		end
+
+		-- 先淘汰本次写入之外的条目，避免刚写入的条目（LFU 下次数最少）被立即淘汰
+		local max = tonumber(ARGV[6])
+		local kept = {}
+		while redis.call('ZCARD', KEYS[2]) + #kept / 2 > max do
+			local popped = redis.call('ZPOPMIN', KEYS[2])
+			if #popped == 0 then
+				break
+			end
+			if written[popped[1]] then
+				kept[#kept + 1] = popped[2]
+				kept[#kept + 1] = popped[1]
+			else
+				evict(popped[1])
+			end
+		end
+		if #kept > 0 then
+			redis.call('ZADD', KEYS[2], unpack(kept))
+		end
+
+		-- 本次写入的条目数超过容量时，在写入的条目之间淘汰
+		while redis.call('ZCARD', KEYS[2]) > max do
+			evict(redis.call('ZPOPMIN', KEYS[2])[1])
+		end
+		return evicted
+	`
+
+	args := append(c.store.scriptArgs(), c.tagPrefix(), expire.Milliseconds(), c.config.MaxEntries,
+		c.evictionPolicyName(), time.Now().UnixMilli())
+	for key, data := range entries {
+		args = append(args, key, data)
+	}
+
+	evicted, err := redis.Strings(c.runEntryScript(script, args))
+	if err != nil {
+		return err
+	}
+	if len(evicted) > 0 {
+		c.record(cacheStatEvictions, int64(len(evicted)))
+		return c.invalidate(false, evicted...)
+	}
+	return nil
+}
+
+// touch 记录条目被访问，用于淘汰策略
+func (c *Cache[T]) touch(keys ...string) {
+	if c.config.MaxEntries <= 0 || len(keys) == 0 {
+		return
+	}
+
+	counts := make(map[string]int64, len(keys))
+	for _, key := range keys {
+		counts[key]++
+	}
+	c.touchCounts(counts)
+}
+
+// touchLocal 记录一次本地缓存命中，在下次 flushTouches 时写入访问记录
+func (c *Cache[T]) touchLocal(key string) {
+	if c.touches != nil {
+		c.touches.add(key)
+	}
+}
+
+// flushTouches 把本地缓存命中累积的访问记录写入 Redis
+func (c *Cache[T]) flushTouches() {
+	if c.touches != nil {
+		c.touchCounts(c.touches.take())
+	}
+}
+
+// touchCounts 按访问次数更新访问记录，LRU 只更新访问时间
+func (c *Cache[T]) touchCounts(counts map[string]int64) {
+	if len(counts) == 0 {
+		return
+	}
+
+	// XX 避免为刚被删除的条目重新建立访问记录
+	if c.config.EvictionPolicy == CacheEvictLFU {
+		pipe := c.redis.Pipeline()
+		for key, n := range counts {
+			pipe.Send("ZADD", c.accessName(), "XX", "INCR", n, key)
+		}
+		pipe.Exec()
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	args := make([]interface{}, 0, len(counts)*2+2)
+	args = append(args, c.accessName(), "XX")
+	for key := range counts {
+		args = append(args, now, key)
+	}
+	c.redis.Do("ZADD", args...)
+}
+
+// evictionPolicyName 淘汰策略在脚本中的名称
+func (c *Cache[T]) evictionPolicyName() string {
+	if c.config.EvictionPolicy == CacheEvictLFU {
+		return "lfu"
+	}
+	return "lru"
+}
+
+// accessName 记录条目访问时间（LRU）或访问次数（LFU）的有序集合
+func (c *Cache[T]) accessName() string {
+	return c.baseName + ":access"
+}
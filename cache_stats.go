@@ -0,0 +1,212 @@
+package redisTool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// cacheStatsFlushInterval SharedStats 时统计增量写入 Redis 的默认间隔
+const cacheStatsFlushInterval = time.Second
+
+// CacheStats 缓存统计信息
+type CacheStats struct {
+	Hits        int64         // 命中次数
+	Misses      int64         // 未命中次数
+	Sets        int64         // 写入的条目数
+	Deletes     int64         // 删除的条目数（包括 InvalidateTag）
+	Expirations int64         // ClearExpired 清理的过期条目数，CacheStorageString 由 Redis 自动过期，不计入
+	Evictions   int64         // 因超出 MaxEntries 被淘汰的条目数
+	Loads       int64         // 加载函数的调用次数
+	LoadErrors  int64         // 加载函数返回错误的次数，不包括 ErrNotFound
+	LoadTime    time.Duration // 加载函数的累计耗时
+}
+
+// HitRate 命中率，没有读取时返回 0
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// AvgLoadTime 加载函数的平均耗时，没有加载时返回 0
+func (s CacheStats) AvgLoadTime() time.Duration {
+	if s.Loads == 0 {
+		return 0
+	}
+	return s.LoadTime / time.Duration(s.Loads)
+}
+
+// cacheStat 统计项
+type cacheStat int
+
+const (
+	cacheStatHits cacheStat = iota
+	cacheStatMisses
+	cacheStatSets
+	cacheStatDeletes
+	cacheStatExpirations
+	cacheStatEvictions
+	cacheStatLoads
+	cacheStatLoadErrors
+	cacheStatLoadTime // 纳秒
+	cacheStatCount
+)
+
+// cacheStatNames 统计项在 Redis 统计哈希中的字段名
+var cacheStatNames = [cacheStatCount]string{
+	"hits", "misses", "sets", "deletes", "expirations", "evictions", "loads", "load_errors", "load_time",
+}
+
+// cacheCounters 进程内的统计计数
+type cacheCounters struct {
+	values  [cacheStatCount]atomic.Int64
+	pending [cacheStatCount]atomic.Int64 // 尚未写入 Redis 统计哈希的增量，仅 SharedStats 时累加
+}
+
+// Stats 获取本进程的缓存统计信息
+func (c *Cache[T]) Stats() CacheStats {
+	var values [cacheStatCount]int64
+	for i := range values {
+		values[i] = c.counters.values[i].Load()
+	}
+	return newCacheStats(values)
+}
+
+// GlobalStats 获取所有实例汇总的缓存统计信息，需要配置 SharedStats
+// 先写入本进程尚未写入的增量；其他实例的增量最多延迟 StatsFlushInterval
+func (c *Cache[T]) GlobalStats() (CacheStats, error) {
+	if err := c.FlushStats(); err != nil {
+		return CacheStats{}, err
+	}
+
+	reply, err := redis.Int64Map(c.redis.Do("HGETALL", c.statsName()))
+	if err != nil {
+		return CacheStats{}, err
+	}
+
+	var values [cacheStatCount]int64
+	for i, name := range cacheStatNames {
+		values[i] = reply[name]
+	}
+	return newCacheStats(values), nil
+}
+
+// ResetStats 清零本进程的统计信息，配置了 SharedStats 时同时清零汇总的统计信息
+// 其他实例尚未写入的增量在清零后仍会写入
+func (c *Cache[T]) ResetStats() error {
+	for i := range c.counters.values {
+		c.counters.values[i].Store(0)
+		c.counters.pending[i].Store(0)
+	}
+	if !c.config.SharedStats {
+		return nil
+	}
+	_, err := c.redis.Do("DEL", c.statsName())
+	return err
+}
+
+// FlushStats 把本进程尚未写入的统计增量写入 Redis 统计哈希，未配置 SharedStats 时不执行任何操作
+// 配置了 SharedStats 时后台每隔 StatsFlushInterval 自动写入，通常无需调用
+func (c *Cache[T]) FlushStats() error {
+	if !c.config.SharedStats {
+		return nil
+	}
+
+	var deltas [cacheStatCount]int64
+	args := []interface{}{c.statsName()}
+	for i := range deltas {
+		deltas[i] = c.counters.pending[i].Swap(0)
+		if deltas[i] != 0 {
+			args = append(args, cacheStatNames[i], deltas[i])
+		}
+	}
+	if len(args) == 1 {
+		return nil
+	}
+
+	// 所有增量在一个脚本中写入，失败时全部放回，下次重试
+	script := `
+		for i = 1, #ARGV, 2 do
+			redis.call("HINCRBY", KEYS[1], ARGV[i], ARGV[i + 1])
+		end
+		return 1
+	`
+
+	conn := c.redis.GetConn()
+	defer conn.Close()
+
+	luaScript := redis.NewScript(1, script)
+	if _, err := luaScript.Do(conn, args...); err != nil {
+		for i, n := range deltas {
+			c.counters.pending[i].Add(n)
+		}
+		return err
+	}
+	return nil
+}
+
+// startStatsFlush 启动后台协程，每隔 StatsFlushInterval 写入统计增量，Close 时停止
+func (c *Cache[T]) startStatsFlush() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopStats = cancel
+
+	go func() {
+		ticker := time.NewTicker(c.config.StatsFlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.FlushStats()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// record 累加统计项，配置了 SharedStats 时同时累加待写入 Redis 的增量
+func (c *Cache[T]) record(stat cacheStat, n int64) {
+	if n == 0 {
+		return
+	}
+	c.counters.values[stat].Add(n)
+	if c.config.SharedStats {
+		c.counters.pending[stat].Add(n)
+	}
+}
+
+// recordLoad 记录一次加载的耗时和结果
+func (c *Cache[T]) recordLoad(d time.Duration, err error) {
+	c.record(cacheStatLoads, 1)
+	c.record(cacheStatLoadTime, int64(d))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.record(cacheStatLoadErrors, 1)
+	}
+}
+
+// statsName 汇总统计信息的哈希
+func (c *Cache[T]) statsName() string {
+	return c.baseName + ":stats"
+}
+
+// newCacheStats 按统计项顺序构造 CacheStats
+func newCacheStats(values [cacheStatCount]int64) CacheStats {
+	return CacheStats{
+		Hits:        values[cacheStatHits],
+		Misses:      values[cacheStatMisses],
+		Sets:        values[cacheStatSets],
+		Deletes:     values[cacheStatDeletes],
+		Expirations: values[cacheStatExpirations],
+		Evictions:   values[cacheStatEvictions],
+		Loads:       values[cacheStatLoads],
+		LoadErrors:  values[cacheStatLoadErrors],
+		LoadTime:    time.Duration(values[cacheStatLoadTime]),
+	}
+}
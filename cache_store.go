@@ -0,0 +1,509 @@
+package redisTool
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// cacheScanCount 遍历缓存键时每次 SCAN 的数量
+const cacheScanCount = 1000
+
+// CacheStorage 缓存存储方式
+type CacheStorage int
+
+const (
+	CacheStorageHash   CacheStorage = iota // 所有条目存放在一个 HASH 中，过期时间记录在 ZSET 中，惰性清理（默认）
+	CacheStorageString                     // 每个条目一个 STRING 键，由 Redis 原生过期自动删除
+)
+
+// cacheStore 缓存的底层存储
+// get 同时返回剩余生存时间（没有过期时间时为 -1），条目不存在或已过期时返回 redis.ErrNil
+type cacheStore interface {
+	withRedis(r *Redis) cacheStore
+	set(key string, data []byte, expire time.Duration) error
+	get(key string) ([]byte, time.Duration, error)
+	setMany(entries map[string][]byte, expire time.Duration) error
+	getMany(keys []string) ([][]byte, []time.Duration, error)
+	delete(keys ...string) error
+	exists(key string) bool
+	clear() error
+	clearExpired() (int, error)
+	length() int
+	keys() ([]string, error)
+	getTTL(key string) (time.Duration, bool)
+	setTTL(key string, expire time.Duration) error
+	scriptArgs() []interface{}
+}
+
+// newCacheStore 按配置创建缓存存储
+func newCacheStore(r *Redis, baseName string, storage CacheStorage) cacheStore {
+	if storage == CacheStorageString {
+		return &stringCacheStore{
+			redis:  r,
+			prefix: baseName + ":entry:",
+		}
+	}
+	return &hashCacheStore{
+		redis:      r,
+		dataName:   baseName + ":data",
+		expireName: baseName + ":expire",
+	}
+}
+
+// hashCacheStore 使用一个 HASH 存放数据、一个 ZSET 记录过期时间
+type hashCacheStore struct {
+	redis      *Redis
+	dataName   string
+	expireName string
+}
+
+func (s *hashCacheStore) withRedis(r *Redis) cacheStore {
+	sc := *s
+	sc.redis = r
+	return &sc
+}
+
+func (s *hashCacheStore) set(key string, data []byte, expire time.Duration) error {
+	conn := s.redis.GetConn()
+	defer conn.Close()
+
+	// 设置数据
+	if _, err := conn.Do("HSET", s.dataName, key, data); err != nil {
+		return err
+	}
+
+	// 设置过期时间
+	if expire > 0 {
+		expireTime := float64(time.Now().Add(expire).UnixMilli())
+		if _, err := conn.Do("ZADD", s.expireName, expireTime, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *hashCacheStore) get(key string) ([]byte, time.Duration, error) {
+	ttl := time.Duration(-1)
+	score, err := redis.Float64(s.redis.Do("ZSCORE", s.expireName, key))
+	if err == nil {
+		// 检查是否过期
+		ttl = time.Until(time.UnixMilli(int64(score)))
+		if ttl <= 0 {
+			s.delete(key)
+			return nil, 0, redis.ErrNil
+		}
+	}
+
+	data, err := redis.Bytes(s.redis.Do("HGET", s.dataName, key))
+	return data, ttl, err
+}
+
+func (s *hashCacheStore) setMany(entries map[string][]byte, expire time.Duration) error {
+	dataArgs := make([]interface{}, 0, len(entries)*2+1)
+	dataArgs = append(dataArgs, s.dataName)
+	expireArgs := make([]interface{}, 0, len(entries)*2+1)
+	expireArgs = append(expireArgs, s.expireName)
+
+	expireTime := float64(time.Now().Add(expire).UnixMilli())
+	for key, data := range entries {
+		dataArgs = append(dataArgs, key, data)
+		expireArgs = append(expireArgs, expireTime, key)
+	}
+
+	pipe := s.redis.Pipeline()
+	pipe.Send("HSET", dataArgs...)
+	if expire > 0 {
+		pipe.Send("ZADD", expireArgs...)
+	}
+	return pipe.Exec()
+}
+
+// getMany 在一个脚本中检查过期并读取数据，过期的条目同时被删除
+func (s *hashCacheStore) getMany(keys []string) ([][]byte, []time.Duration, error) {
+	script := `
+		local now = tonumber(ARGV[1])
+		local result = {}
+		for i = 2, #ARGV do
+			local key = ARGV[i]
+			local data = false
+			local ttl = -1
+			local score = redis.call('ZSCORE', KEYS[2], key)
+			if score and tonumber(score) <= now then
+				redis.call('HDEL', KEYS[1], key)
+				redis.call('ZREM', KEYS[2], key)
+			else
+				data = redis.call('HGET', KEYS[1], key)
+				if score then
+					ttl = tonumber(score) - now
+				end
+			end
+			result[#result + 1] = data
+			result[#result + 1] = ttl
+		end
+		return result
+	`
+
+	args := make([]interface{}, 0, len(keys)+3)
+	args = append(args, s.dataName, s.expireName, time.Now().UnixMilli())
+	for _, key := range keys {
+		args = append(args, key)
+	}
+
+	conn := s.redis.GetConn()
+	defer conn.Close()
+
+	luaScript := redis.NewScript(2, script)
+	values, err := redis.Values(luaScript.Do(conn, args...))
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseCacheEntries(values, len(keys))
+}
+
+func (s *hashCacheStore) delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	conn := s.redis.GetConn()
+	defer conn.Close()
+
+	// 删除数据
+	dataArgs := make([]interface{}, 0, len(keys)+1)
+	dataArgs = append(dataArgs, s.dataName)
+	for _, key := range keys {
+		dataArgs = append(dataArgs, key)
+	}
+	if _, err := conn.Do("HDEL", dataArgs...); err != nil {
+		return err
+	}
+
+	// 删除过期时间
+	expireArgs := make([]interface{}, 0, len(keys)+1)
+	expireArgs = append(expireArgs, s.expireName)
+	for _, key := range keys {
+		expireArgs = append(expireArgs, key)
+	}
+	if _, err := conn.Do("ZREM", expireArgs...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *hashCacheStore) exists(key string) bool {
+	if s.isExpired(key) {
+		s.delete(key)
+		return false
+	}
+
+	exists, err := redis.Int(s.redis.Do("HEXISTS", s.dataName, key))
+	if err != nil {
+		return false
+	}
+	return exists == 1
+}
+
+func (s *hashCacheStore) clear() error {
+	_, err := s.redis.Do("DEL", s.dataName, s.expireName)
+	return err
+}
+
+func (s *hashCacheStore) clearExpired() (int, error) {
+	now := float64(time.Now().UnixMilli())
+
+	// 获取过期的键
+	keys, err := redis.Strings(s.redis.Do("ZRANGEBYSCORE", s.expireName, 0, now))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(keys) > 0 {
+		if err := s.delete(keys...); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(keys), nil
+}
+
+func (s *hashCacheStore) length() int {
+	s.clearExpired() // 清理过期缓存
+
+	length, err := redis.Int(s.redis.Do("HLEN", s.dataName))
+	if err != nil {
+		return 0
+	}
+	return length
+}
+
+func (s *hashCacheStore) keys() ([]string, error) {
+	s.clearExpired() // 清理过期缓存
+
+	return redis.Strings(s.redis.Do("HKEYS", s.dataName))
+}
+
+func (s *hashCacheStore) getTTL(key string) (time.Duration, bool) {
+	score, err := redis.Float64(s.redis.Do("ZSCORE", s.expireName, key))
+	if err != nil {
+		return 0, false
+	}
+
+	ttl := time.Until(time.UnixMilli(int64(score)))
+	if ttl <= 0 {
+		return 0, false
+	}
+	return ttl, true
+}
+
+func (s *hashCacheStore) setTTL(key string, expire time.Duration) error {
+	if !s.exists(key) {
+		return nil
+	}
+
+	expireTime := float64(time.Now().Add(expire).UnixMilli())
+	_, err := s.redis.Do("ZADD", s.expireName, expireTime, key)
+	return err
+}
+
+// scriptArgs 返回 Lua 脚本中访问条目所需的参数，见 cacheEntryScript
+func (s *hashCacheStore) scriptArgs() []interface{} {
+	return []interface{}{"hash", s.dataName, s.expireName}
+}
+
+// isExpired 判断是否过期
+func (s *hashCacheStore) isExpired(key string) bool {
+	score, err := redis.Float64(s.redis.Do("ZSCORE", s.expireName, key))
+	if err != nil {
+		return false
+	}
+
+	return time.Now().After(time.UnixMilli(int64(score)))
+}
+
+// stringCacheStore 每个条目一个 STRING 键，过期由 Redis 处理，不需要清理
+type stringCacheStore struct {
+	redis  *Redis
+	prefix string
+}
+
+func (s *stringCacheStore) withRedis(r *Redis) cacheStore {
+	sc := *s
+	sc.redis = r
+	return &sc
+}
+
+func (s *stringCacheStore) set(key string, data []byte, expire time.Duration) error {
+	var err error
+	if expire > 0 {
+		_, err = s.redis.Do("SET", s.prefix+key, data, "PX", expire.Milliseconds())
+	} else {
+		_, err = s.redis.Do("SET", s.prefix+key, data)
+	}
+	return err
+}
+
+func (s *stringCacheStore) get(key string) ([]byte, time.Duration, error) {
+	pipe := s.redis.Pipeline()
+	dataResult := pipe.Send("GET", s.prefix+key)
+	ttlResult := pipe.Send("PTTL", s.prefix+key)
+	pipe.Exec()
+
+	data, err := dataResult.Bytes()
+	if err != nil {
+		return nil, 0, err
+	}
+	ttl := time.Duration(-1)
+	if ms, err := ttlResult.Int64(); err == nil && ms >= 0 {
+		ttl = time.Duration(ms) * time.Millisecond
+	}
+	return data, ttl, nil
+}
+
+func (s *stringCacheStore) setMany(entries map[string][]byte, expire time.Duration) error {
+	pipe := s.redis.Pipeline()
+	for key, data := range entries {
+		if expire > 0 {
+			pipe.Send("SET", s.prefix+key, data, "PX", expire.Milliseconds())
+		} else {
+			pipe.Send("SET", s.prefix+key, data)
+		}
+	}
+	return pipe.Exec()
+}
+
+func (s *stringCacheStore) getMany(keys []string) ([][]byte, []time.Duration, error) {
+	pipe := s.redis.Pipeline()
+	results := make([]*Result, 0, len(keys)*2)
+	for _, key := range keys {
+		results = append(results, pipe.Send("GET", s.prefix+key), pipe.Send("PTTL", s.prefix+key))
+	}
+	if err := pipe.Exec(); err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]interface{}, len(results))
+	for i, result := range results {
+		values[i], _ = result.Value()
+	}
+	return parseCacheEntries(values, len(keys))
+}
+
+func (s *stringCacheStore) delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		args[i] = s.prefix + key
+	}
+	_, err := s.redis.Do("DEL", args...)
+	return err
+}
+
+func (s *stringCacheStore) exists(key string) bool {
+	exists, err := redis.Int(s.redis.Do("EXISTS", s.prefix+key))
+	if err != nil {
+		return false
+	}
+	return exists == 1
+}
+
+func (s *stringCacheStore) clear() error {
+	return s.scan(func(names []string) error {
+		args := make([]interface{}, len(names))
+		for i, name := range names {
+			args[i] = name
+		}
+		_, err := s.redis.Do("DEL", args...)
+		return err
+	})
+}
+
+// clearExpired 过期的键由 Redis 自动删除，无需清理
+func (s *stringCacheStore) clearExpired() (int, error) {
+	return 0, nil
+}
+
+func (s *stringCacheStore) length() int {
+	length := 0
+	if err := s.scan(func(names []string) error {
+		length += len(names)
+		return nil
+	}); err != nil {
+		return 0
+	}
+	return length
+}
+
+func (s *stringCacheStore) keys() ([]string, error) {
+	keys := make([]string, 0)
+	err := s.scan(func(names []string) error {
+		for _, name := range names {
+			keys = append(keys, strings.TrimPrefix(name, s.prefix))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *stringCacheStore) getTTL(key string) (time.Duration, bool) {
+	ms, err := redis.Int64(s.redis.Do("PTTL", s.prefix+key))
+	if err != nil || ms <= 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+func (s *stringCacheStore) setTTL(key string, expire time.Duration) error {
+	_, err := s.redis.Do("PEXPIRE", s.prefix+key, expire.Milliseconds())
+	return err
+}
+
+// scriptArgs 返回 Lua 脚本中访问条目所需的参数，见 cacheEntryScript
+func (s *stringCacheStore) scriptArgs() []interface{} {
+	return []interface{}{"string", s.prefix, ""}
+}
+
+// scan 使用 SCAN 遍历所有条目键，每批调用一次 fn
+func (s *stringCacheStore) scan(fn func(names []string) error) error {
+	pattern := escapeGlob(s.prefix) + "*"
+	seen := make(map[string]bool) // SCAN 可能重复返回同一个键
+	cursor := "0"
+	for {
+		reply, err := redis.Values(s.redis.Do("SCAN", cursor, "MATCH", pattern, "COUNT", cacheScanCount))
+		if err != nil {
+			return err
+		}
+		if len(reply) != 2 {
+			return redis.Error("redisTool: unexpected SCAN reply")
+		}
+
+		cursor, err = redis.String(reply[0], nil)
+		if err != nil {
+			return err
+		}
+		batch, err := redis.Strings(reply[1], nil)
+		if err != nil {
+			return err
+		}
+		names := batch[:0]
+		for _, name := range batch {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			if err := fn(names); err != nil {
+				return err
+			}
+		}
+
+		if cursor == "0" {
+			return nil
+		}
+	}
+}
+
+// parseCacheEntries 解析 [data, ttl, ...] 格式的回复，ttl 单位为毫秒，-1 表示没有过期时间，不存在的条目 data 为 nil
+func parseCacheEntries(values []interface{}, n int) ([][]byte, []time.Duration, error) {
+	if len(values) != n*2 {
+		return nil, nil, redis.Error("redisTool: unexpected cache entries reply")
+	}
+
+	data := make([][]byte, n)
+	ttls := make([]time.Duration, n)
+	for i := 0; i < n; i++ {
+		if values[i*2] != nil {
+			data[i], _ = redis.Bytes(values[i*2], nil)
+		}
+		ms, _ := redis.Int64(values[i*2+1], nil)
+		if ms < 0 {
+			ttls[i] = -1
+		} else {
+			ttls[i] = time.Duration(ms) * time.Millisecond
+		}
+	}
+	return data, ttls, nil
+}
+
+// escapeGlob 转义 SCAN MATCH 模式中的特殊字符
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,198 @@
+package redisTool
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// cacheEntryScript Lua 脚本中访问缓存条目的函数
+// KEYS[1] 为标签哈希，KEYS[2] 为访问记录，ARGV[1..3] 为 cacheStore.scriptArgs 的返回值，ARGV[4] 为标签集合前缀
+const cacheEntryScript = `
+	local function entryExists(key)
+		if ARGV[1] == 'hash' then
+			return redis.call('HEXISTS', ARGV[2], key) == 1
+		end
+		return redis.call('EXISTS', ARGV[2] .. key) == 1
+	end
+
+	local function entryRemove(key)
+		if ARGV[1] == 'hash' then
+			redis.call('HDEL', ARGV[2], key)
+			redis.call('ZREM', ARGV[3], key)
+		else
+			redis.call('DEL', ARGV[2] .. key)
+		end
+	end
+
+	local function untag(key)
+		local tags = redis.call('HGET', KEYS[1], key)
+		if tags then
+			for _, tag in ipairs(cjson.decode(tags)) do
+				redis.call('SREM', ARGV[4] .. tag, key)
+			end
+			redis.call('HDEL', KEYS[1], key)
+		end
+	end
+
+	local function forget(key)
+		untag(key)
+		redis.call('ZREM', KEYS[2], key)
+	end
+`
+
+// SetWithTags 设置缓存并关联标签，替换该键原有的标签
+// 之后可以通过 InvalidateTag 一次删除关联了同一标签的所有条目
+func (c *Cache[T]) SetWithTags(key string, value T, expire time.Duration, tags ...string) error {
+	if err := c.Set(key, value, expire); err != nil {
+		return err
+	}
+
+	script := cacheEntryScript + `
+		untag(ARGV[5])
+		local tags = {}
+		for i = 6, #ARGV do
+			tags[#tags + 1] = ARGV[i]
+			redis.call('SADD', ARGV[4] .. ARGV[i], ARGV[5])
+		end
+		if #tags > 0 then
+			redis.call('HSET', KEYS[1], ARGV[5], cjson.encode(tags))
+		end
+		return #tags
+	`
+
+	args := append(c.store.scriptArgs(), c.tagPrefix(), key)
+	for _, tag := range tags {
+		args = append(args, tag)
+	}
+	_, err := c.runEntryScript(script, args)
+	return err
+}
+
+// InvalidateTag 原子地删除关联了该标签的所有条目，返回删除的条目数量
+func (c *Cache[T]) InvalidateTag(tag string) (int, error) {
+	script := cacheEntryScript + `
+		local tagName = ARGV[4] .. ARGV[5]
+		local keys = redis.call('SMEMBERS', tagName)
+		for _, key in ipairs(keys) do
+			entryRemove(key)
+			forget(key)
+		end
+		redis.call('DEL', tagName)
+		return keys
+	`
+
+	keys, err := redis.Strings(c.runEntryScript(script, append(c.store.scriptArgs(), c.tagPrefix(), tag)))
+	if err != nil {
+		return 0, err
+	}
+	c.record(cacheStatDeletes, int64(len(keys)))
+	if err := c.invalidate(false, keys...); err != nil {
+		return len(keys), err
+	}
+	return len(keys), nil
+}
+
+// forget 删除键的标签和访问记录
+func (c *Cache[T]) forget(keys ...string) error {
+	script := cacheEntryScript + `
+		for i = 5, #ARGV do
+			forget(ARGV[i])
+		end
+		return 1
+	`
+
+	args := append(c.store.scriptArgs(), c.tagPrefix())
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	_, err := c.runEntryScript(script, args)
+	return err
+}
+
+// sweep 遍历标签哈希（HSCAN）或访问记录（ZSCAN），清理已过期或已删除条目的标签和访问记录
+func (c *Cache[T]) sweep(command, name string) error {
+	script := cacheEntryScript + `
+		local removed = 0
+		for i = 5, #ARGV do
+			if not entryExists(ARGV[i]) then
+				forget(ARGV[i])
+				removed = removed + 1
+			end
+		end
+		return removed
+	`
+
+	cursor := "0"
+	for {
+		reply, err := redis.Values(c.redis.Do(command, name, cursor, "COUNT", cacheScanCount))
+		if err != nil {
+			return err
+		}
+		if len(reply) != 2 {
+			return redis.Error("redisTool: unexpected " + command + " reply")
+		}
+		if cursor, err = redis.String(reply[0], nil); err != nil {
+			return err
+		}
+		fields, err := redis.Strings(reply[1], nil)
+		if err != nil {
+			return err
+		}
+
+		// HSCAN 和 ZSCAN 都返回 [member, value, ...]
+		if len(fields) > 0 {
+			args := append(c.store.scriptArgs(), c.tagPrefix())
+			for i := 0; i < len(fields); i += 2 {
+				args = append(args, fields[i])
+			}
+			if _, err := c.runEntryScript(script, args); err != nil {
+				return err
+			}
+		}
+
+		if cursor == "0" {
+			return nil
+		}
+	}
+}
+
+// clearMeta 删除所有标签和访问记录
+func (c *Cache[T]) clearMeta() error {
+	script := `
+		local all = redis.call('HGETALL', KEYS[1])
+		for i = 2, #all, 2 do
+			for _, tag in ipairs(cjson.decode(all[i])) do
+				redis.call('DEL', ARGV[1] .. tag)
+			end
+		end
+		redis.call('DEL', KEYS[1], KEYS[2])
+		return 1
+	`
+
+	conn := c.redis.GetConn()
+	defer conn.Close()
+
+	luaScript := redis.NewScript(2, script)
+	_, err := luaScript.Do(conn, c.tagsName(), c.accessName(), c.tagPrefix())
+	return err
+}
+
+// runEntryScript 执行基于 cacheEntryScript 的脚本
+func (c *Cache[T]) runEntryScript(script string, args []interface{}) (interface{}, error) {
+	conn := c.redis.GetConn()
+	defer conn.Close()
+
+	luaScript := redis.NewScript(2, script)
+	return luaScript.Do(conn, append([]interface{}{c.tagsName(), c.accessName()}, args...)...)
+}
+
+// tagsName 记录每个键所关联标签的哈希
+func (c *Cache[T]) tagsName() string {
+	return c.baseName + ":tags"
+}
+
+// tagPrefix 标签集合的键名前缀，每个标签一个集合，记录关联的键
+func (c *Cache[T]) tagPrefix() string {
+	return c.baseName + ":tag:"
+}
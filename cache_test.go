@@ -1,8 +1,14 @@
 package redisTool
 
 import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/gomodule/redigo/redis"
 )
 
 func TestCache_SetGet(t *testing.T) {
@@ -376,3 +382,926 @@ func TestCache_NoExpire(t *testing.T) {
 		t.Errorf("Get() Name = %v, want Alice", value.Name)
 	}
 }
+
+func TestCache_StringStorage(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("test*cache", CacheConfig{
+		DefaultExpire: time.Minute,
+		Storage:       CacheStorageString,
+	}, tr.Redis)
+	other := NewCache[TestStruct]("test?cache", CacheConfig{
+		Storage: CacheStorageString,
+	}, tr.Redis)
+	other.Set("other", TestStruct{Name: "Other"}, 0)
+
+	cache.Set("key1", TestStruct{Name: "Alice", Age: 30}, time.Second)
+	cache.Set("key2", TestStruct{Name: "Bob", Age: 25}, 0)
+
+	value, ok := cache.Get("key1")
+	if !ok || value.Name != "Alice" {
+		t.Errorf("Get() = %v, %v, want Alice", value.Name, ok)
+	}
+	if !cache.Exists("key2") {
+		t.Error("Exists() should return true for key2")
+	}
+	if length := cache.Length(); length != 2 {
+		t.Errorf("Length() = %v, want 2", length)
+	}
+	keys, err := cache.Keys()
+	if err != nil || len(keys) != 2 {
+		t.Errorf("Keys() = %v, %v, want 2 keys", keys, err)
+	}
+
+	// key2 使用默认过期时间
+	ttl, ok := cache.GetTTL("key2")
+	if !ok || ttl <= time.Second || ttl > time.Minute {
+		t.Errorf("GetTTL(key2) = %v, %v, want about 1m", ttl, ok)
+	}
+
+	// 每个条目是独立的 Redis 键，由 Redis 自动过期
+	if tr.MiniRedis != nil {
+		tr.MiniRedis.FastForward(time.Second * 2)
+	} else {
+		time.Sleep(time.Second * 2)
+	}
+	if _, ok := cache.Get("key1"); ok {
+		t.Error("Get() should return false for expired key1")
+	}
+	if length := cache.Length(); length != 1 {
+		t.Errorf("Length() after expiry = %v, want 1", length)
+	}
+
+	if err := cache.SetTTL("key2", time.Hour); err != nil {
+		t.Fatalf("SetTTL() error = %v", err)
+	}
+	if ttl, ok := cache.GetTTL("key2"); !ok || ttl <= time.Minute {
+		t.Errorf("GetTTL(key2) after SetTTL = %v, %v, want about 1h", ttl, ok)
+	}
+
+	cache.Delete("key2")
+	if cache.Exists("key2") {
+		t.Error("Exists() should return false after Delete")
+	}
+
+	cache.Set("key3", TestStruct{Name: "Carol"}, 0)
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if length := cache.Length(); length != 0 {
+		t.Errorf("Length() after Clear = %v, want 0", length)
+	}
+
+	// 名称中的通配符不会匹配到其他缓存
+	if !other.Exists("other") {
+		t.Error("Clear() removed entries of another cache")
+	}
+}
+
+func TestCache_GetOrSetSingleFlight(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{}, tr.Redis)
+
+	var calls atomic.Int32
+	factory := func(key string) (TestStruct, time.Duration) {
+		calls.Add(1)
+		time.Sleep(time.Millisecond * 100)
+		return TestStruct{Name: "Alice", Age: 30}, time.Minute
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value := cache.GetOrSet("hot", factory); value.Name != "Alice" {
+				t.Errorf("GetOrSet() Name = %v, want Alice", value.Name)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("factory called %d times, want 1", n)
+	}
+}
+
+func TestCache_GetOrSetCrossProcess(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	// 两个缓存实例模拟两个进程，各自有独立的进程内去重
+	cache1 := NewCache[TestStruct]("testcache", CacheConfig{}, tr.Redis)
+	cache2 := NewCache[TestStruct]("testcache", CacheConfig{}, tr.Redis)
+
+	var calls atomic.Int32
+	factory := func(key string) (TestStruct, time.Duration) {
+		calls.Add(1)
+		time.Sleep(time.Millisecond * 200)
+		return TestStruct{Name: "Alice", Age: 30}, time.Minute
+	}
+
+	var wg sync.WaitGroup
+	for _, cache := range []*Cache[TestStruct]{cache1, cache2} {
+		wg.Add(1)
+		go func(cache *Cache[TestStruct]) {
+			defer wg.Done()
+			if value := cache.GetOrSet("hot", factory); value.Name != "Alice" {
+				t.Errorf("GetOrSet() Name = %v, want Alice", value.Name)
+			}
+		}(cache)
+	}
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("factory called %d times, want 1", n)
+	}
+}
+
+func TestCache_GetOrSetWaitTimeout(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{
+		LoadWaitTime: time.Millisecond * 200,
+	}, tr.Redis)
+
+	// 其他进程持有加载锁但一直没有写入结果
+	lock := tr.Redis.NewLock("testcache:hot", LockConfig{WaitTime: time.Minute})
+	if !lock.TryLock() {
+		t.Fatal("TryLock() failed")
+	}
+	defer lock.Unlock()
+
+	start := time.Now()
+	value := cache.GetOrSet("hot", func(key string) (TestStruct, time.Duration) {
+		return TestStruct{Name: "Bob", Age: 25}, time.Minute
+	})
+	if value.Name != "Bob" {
+		t.Errorf("GetOrSet() Name = %v, want Bob", value.Name)
+	}
+	if elapsed := time.Since(start); elapsed < time.Millisecond*200 {
+		t.Errorf("GetOrSet() returned after %v, want to wait LoadWaitTime", elapsed)
+	}
+}
+
+func TestCache_GetOrSetRedisDown(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+	if tr.MiniRedis == nil {
+		t.Skip("redis outage test requires miniredis")
+	}
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{
+		LoadWaitTime: time.Second,
+	}, tr.Redis)
+	tr.MiniRedis.Close()
+
+	// 获取加载锁出错时不等待 LoadWaitTime，直接调用 factory
+	start := time.Now()
+	value := cache.GetOrSet("key1", func(key string) (TestStruct, time.Duration) {
+		return TestStruct{Name: "Alice"}, time.Minute
+	})
+	if value.Name != "Alice" {
+		t.Errorf("GetOrSet() Name = %v, want Alice", value.Name)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("GetOrSet() returned after %v, want no wait", elapsed)
+	}
+}
+
+func TestCache_StaleWhileRevalidate(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{
+		StaleTime: time.Second * 10,
+	}, tr.Redis)
+
+	refreshed := make(chan string, 1)
+	cache.SetLoader(func(key string) (TestStruct, time.Duration) {
+		refreshed <- key
+		return TestStruct{Name: "Fresh"}, time.Minute
+	})
+
+	cache.Set("key1", TestStruct{Name: "Old"}, time.Millisecond*100)
+	if ttl, ok := cache.GetTTL("key1"); !ok || ttl > time.Millisecond*100 {
+		t.Errorf("GetTTL() = %v, %v, want <= 100ms excluding StaleTime", ttl, ok)
+	}
+
+	// 软过期之前不刷新
+	if value, ok := cache.Get("key1"); !ok || value.Name != "Old" {
+		t.Fatalf("Get() = %v, %v, want Old", value.Name, ok)
+	}
+	select {
+	case <-refreshed:
+		t.Fatal("loader should not run before soft expiry")
+	case <-time.After(time.Millisecond * 20):
+	}
+
+	time.Sleep(time.Millisecond * 150)
+
+	// 软过期之后仍返回旧值，并在后台刷新
+	if value, ok := cache.Get("key1"); !ok || value.Name != "Old" {
+		t.Fatalf("Get() after soft expiry = %v, %v, want stale Old", value.Name, ok)
+	}
+	select {
+	case key := <-refreshed:
+		if key != "key1" {
+			t.Errorf("loader key = %v, want key1", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("loader was not called after soft expiry")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if value, _ := cache.Get("key1"); value.Name == "Fresh" {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Error("Get() did not return refreshed value")
+}
+
+func TestCache_GetOrSetStale(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{
+		StaleTime: time.Second * 10,
+	}, tr.Redis)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	factory := func(key string) (TestStruct, time.Duration) {
+		calls.Add(1)
+		<-release
+		return TestStruct{Name: "Fresh"}, time.Minute
+	}
+
+	cache.Set("key1", TestStruct{Name: "Old"}, time.Millisecond*50)
+	time.Sleep(time.Millisecond * 100)
+
+	// 刷新期间所有调用者都立即拿到旧值，factory 只执行一次
+	for i := 0; i < 5; i++ {
+		if value := cache.GetOrSet("key1", factory); value.Name != "Old" {
+			t.Errorf("GetOrSet() = %v, want stale Old", value.Name)
+		}
+	}
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if value, _ := cache.Get("key1"); value.Name == "Fresh" {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("factory called %d times, want 1", n)
+	}
+}
+
+func TestCache_EarlyRefresh(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{
+		EarlyRefreshBeta: 1e9,
+	}, tr.Redis)
+
+	var calls atomic.Int32
+	factory := func(key string) (TestStruct, time.Duration) {
+		calls.Add(1)
+		time.Sleep(time.Millisecond)
+		return TestStruct{Name: "Alice"}, time.Minute
+	}
+
+	// 第一次加载记录加载耗时
+	cache.GetOrSet("key1", factory)
+
+	// 系数极大时，距离过期还很久也会提前刷新
+	cache.GetOrSet("key1", factory)
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("factory called %d times, want 2 with early refresh", n)
+	}
+
+	// 不设置过期时间的条目不会提前刷新
+	cache.Set("forever", TestStruct{Name: "Bob"}, 0)
+	cache.GetOrSet("forever", factory)
+	time.Sleep(time.Millisecond * 50)
+	if n := calls.Load(); n != 2 {
+		t.Errorf("factory called %d times, want no refresh for entry without TTL", n)
+	}
+}
+
+func TestCache_LocalTier(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache1 := NewCache[TestStruct]("testcache", CacheConfig{LocalSize: 100}, tr.Redis)
+	defer cache1.Close()
+	cache2 := NewCache[TestStruct]("testcache", CacheConfig{LocalSize: 100}, tr.Redis)
+	defer cache2.Close()
+
+	cache1.Set("key1", TestStruct{Name: "Alice"}, time.Minute)
+	time.Sleep(time.Millisecond * 50) // 等待 Set 的失效通知送达，避免清掉下面写入的本地条目
+	if value, ok := cache2.Get("key1"); !ok || value.Name != "Alice" {
+		t.Fatalf("Get() = %v, %v, want Alice", value.Name, ok)
+	}
+
+	// 本地命中不访问 Redis
+	tr.Redis.Do("DEL", tr.Redis.CreateName(RedisTypeCache_, "testcache")+":data")
+	if value, ok := cache2.Get("key1"); !ok || value.Name != "Alice" {
+		t.Fatalf("Get() from local tier = %v, %v, want Alice", value.Name, ok)
+	}
+
+	waitFor := func(cond func() bool) bool {
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			if cond() {
+				return true
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		return false
+	}
+
+	// 其他实例写入后本地缓存失效
+	cache1.Set("key1", TestStruct{Name: "Bob"}, time.Minute)
+	if !waitFor(func() bool {
+		value, _ := cache2.Get("key1")
+		return value.Name == "Bob"
+	}) {
+		t.Error("Set() on another instance did not invalidate local tier")
+	}
+
+	cache1.Delete("key1")
+	if !waitFor(func() bool {
+		_, ok := cache2.Get("key1")
+		return !ok
+	}) {
+		t.Error("Delete() on another instance did not invalidate local tier")
+	}
+
+	cache2.Set("key2", TestStruct{Name: "Carol"}, time.Minute)
+	cache2.Get("key2")
+	cache1.Clear()
+	if !waitFor(func() bool {
+		_, ok := cache2.Get("key2")
+		return !ok
+	}) {
+		t.Error("Clear() on another instance did not invalidate local tier")
+	}
+}
+
+func TestCache_GetOrLoad(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{}, tr.Redis)
+
+	value, err := cache.GetOrLoad("key1", func(key string) (TestStruct, time.Duration, error) {
+		return TestStruct{Name: "Alice"}, time.Minute, nil
+	})
+	if err != nil || value.Name != "Alice" {
+		t.Fatalf("GetOrLoad() = %v, %v, want Alice", value.Name, err)
+	}
+	if value, ok := cache.Get("key1"); !ok || value.Name != "Alice" {
+		t.Errorf("Get() = %v, %v, want Alice", value.Name, ok)
+	}
+
+	// 加载错误返回给调用者，不写入缓存
+	backendErr := errors.New("backend down")
+	var calls atomic.Int32
+	failing := func(key string) (TestStruct, time.Duration, error) {
+		calls.Add(1)
+		return TestStruct{Name: "garbage"}, time.Minute, backendErr
+	}
+	for i := 0; i < 2; i++ {
+		value, err := cache.GetOrLoad("key2", failing)
+		if !errors.Is(err, backendErr) {
+			t.Errorf("GetOrLoad() error = %v, want backend error", err)
+		}
+		if value.Name != "" {
+			t.Errorf("GetOrLoad() value = %v, want zero value on error", value.Name)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("loader called %d times, want 2 (errors are not cached)", n)
+	}
+	if cache.Exists("key2") {
+		t.Error("Exists() should return false after failed load")
+	}
+}
+
+func TestCache_GetOrLoadNegative(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{
+		DefaultExpire:  time.Minute,
+		NegativeExpire: time.Millisecond * 200,
+	}, tr.Redis)
+
+	var calls atomic.Int32
+	loader := func(key string) (TestStruct, time.Duration, error) {
+		if calls.Add(1) == 1 {
+			return TestStruct{}, 0, fmt.Errorf("user %s: %w", key, ErrNotFound)
+		}
+		return TestStruct{Name: "Alice"}, 0, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.GetOrLoad("user1", loader); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetOrLoad() error = %v, want ErrNotFound", err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("loader called %d times, want 1 while negative entry is cached", n)
+	}
+	if _, ok := cache.Get("user1"); ok {
+		t.Error("Get() should miss for negative entry")
+	}
+	if cache.Exists("user1") {
+		t.Error("Exists() should return false for negative entry")
+	}
+	if ttl, ok := cache.GetTTL("user1"); !ok || ttl > time.Millisecond*200 {
+		t.Errorf("GetTTL() = %v, %v, want NegativeExpire", ttl, ok)
+	}
+
+	// 负缓存过期后重新加载
+	time.Sleep(time.Millisecond * 250)
+	value, err := cache.GetOrLoad("user1", loader)
+	if err != nil || value.Name != "Alice" {
+		t.Errorf("GetOrLoad() after negative expiry = %v, %v, want Alice", value.Name, err)
+	}
+}
+
+func TestCache_NegativeNotCounted(t *testing.T) {
+	for _, storage := range []CacheStorage{CacheStorageHash, CacheStorageString} {
+		tr := NewTestRedis(t)
+
+		cache := NewCache[TestStruct]("testcache", CacheConfig{
+			Storage:        storage,
+			NegativeExpire: time.Minute,
+		}, tr.Redis)
+
+		cache.Set("key1", TestStruct{Name: "Alice"}, time.Minute)
+		cache.GetOrLoad("missing", func(key string) (TestStruct, time.Duration, error) {
+			return TestStruct{}, 0, ErrNotFound
+		})
+
+		// “不存在”标记不计入 Keys 和 Length，与 Exists 一致
+		keys, err := cache.Keys()
+		if err != nil || len(keys) != 1 || keys[0] != "key1" {
+			t.Errorf("storage %v: Keys() = %v, %v, want [key1]", storage, keys, err)
+		}
+		if length := cache.Length(); length != 1 {
+			t.Errorf("storage %v: Length() = %d, want 1", storage, length)
+		}
+
+		tr.Close()
+	}
+}
+
+func TestCache_InvalidateTag(t *testing.T) {
+	for _, storage := range []CacheStorage{CacheStorageHash, CacheStorageString} {
+		tr := NewTestRedis(t)
+
+		cache := NewCache[TestStruct]("testcache", CacheConfig{Storage: storage}, tr.Redis)
+
+		cache.SetWithTags("user:1", TestStruct{Name: "Alice"}, time.Minute, "user:1")
+		cache.SetWithTags("user:1:posts", TestStruct{Name: "Posts"}, time.Minute, "user:1", "posts")
+		cache.SetWithTags("user:2", TestStruct{Name: "Bob"}, time.Minute, "user:2")
+		cache.Set("plain", TestStruct{Name: "Plain"}, time.Minute)
+
+		count, err := cache.InvalidateTag("user:1")
+		if err != nil {
+			t.Fatalf("InvalidateTag() error = %v", err)
+		}
+		if count != 2 {
+			t.Errorf("InvalidateTag() = %v, want 2", count)
+		}
+		if cache.Exists("user:1") || cache.Exists("user:1:posts") {
+			t.Errorf("storage %v: tagged entries should be removed", storage)
+		}
+		if !cache.Exists("user:2") || !cache.Exists("plain") {
+			t.Errorf("storage %v: untagged entries should remain", storage)
+		}
+
+		// 被删除条目的其他标签也一并清理
+		if count, _ := cache.InvalidateTag("posts"); count != 0 {
+			t.Errorf("InvalidateTag(posts) = %v, want 0", count)
+		}
+
+		// 重新设置标签会替换原有标签
+		cache.SetWithTags("user:2", TestStruct{Name: "Bob"}, time.Minute, "team")
+		if count, _ := cache.InvalidateTag("user:2"); count != 0 {
+			t.Errorf("InvalidateTag(user:2) after retag = %v, want 0", count)
+		}
+		if count, _ := cache.InvalidateTag("team"); count != 1 {
+			t.Errorf("InvalidateTag(team) = %v, want 1", count)
+		}
+
+		tr.Close()
+	}
+}
+
+func TestCache_TagCleanup(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{}, tr.Redis)
+	tagsName := cache.tagsName()
+
+	cache.SetWithTags("key1", TestStruct{Name: "Alice"}, time.Millisecond*50, "group")
+	cache.SetWithTags("key2", TestStruct{Name: "Bob"}, time.Minute, "group")
+	cache.SetWithTags("key3", TestStruct{Name: "Carol"}, time.Minute, "group")
+
+	// Delete 同时删除标签
+	cache.Delete("key3")
+	if exists, _ := tr.Redis.Do("HEXISTS", tagsName, "key3"); exists != int64(0) {
+		t.Error("Delete() should remove tags of key3")
+	}
+
+	// 条目过期后 ClearExpired 清理标签
+	time.Sleep(time.Millisecond * 100)
+	if err := cache.ClearExpired(); err != nil {
+		t.Fatalf("ClearExpired() error = %v", err)
+	}
+	members, _ := tr.Redis.Do("SCARD", cache.tagPrefix()+"group")
+	if members != int64(1) {
+		t.Errorf("tag set size after ClearExpired = %v, want 1", members)
+	}
+
+	// Clear 删除所有标签
+	cache.Clear()
+	keys, _ := tr.Redis.Do("KEYS", cache.baseName+"*")
+	if keys, ok := keys.([]interface{}); !ok || len(keys) != 0 {
+		t.Errorf("keys after Clear = %v, want none", keys)
+	}
+}
+
+func TestCache_SetManyGetMany(t *testing.T) {
+	for _, storage := range []CacheStorage{CacheStorageHash, CacheStorageString} {
+		tr := NewTestRedis(t)
+
+		cache := NewCache[TestStruct]("testcache", CacheConfig{
+			Storage:        storage,
+			NegativeExpire: time.Minute,
+		}, tr.Redis)
+
+		err := cache.SetMany(map[string]TestStruct{
+			"key1": {Name: "Alice", Age: 30},
+			"key2": {Name: "Bob", Age: 25},
+		}, time.Minute)
+		if err != nil {
+			t.Fatalf("SetMany() error = %v", err)
+		}
+		cache.Set("short", TestStruct{Name: "Short"}, time.Millisecond*50)
+		cache.GetOrLoad("negative", func(key string) (TestStruct, time.Duration, error) {
+			return TestStruct{}, 0, ErrNotFound
+		})
+
+		if ttl, ok := cache.GetTTL("key2"); !ok || ttl > time.Minute {
+			t.Errorf("storage %v: GetTTL(key2) = %v, %v, want about 1m", storage, ttl, ok)
+		}
+
+		if tr.MiniRedis != nil {
+			tr.MiniRedis.FastForward(time.Millisecond * 100)
+		}
+		time.Sleep(time.Millisecond * 100)
+
+		values := cache.GetMany("key1", "key2", "short", "negative", "missing")
+		if len(values) != 2 {
+			t.Errorf("storage %v: GetMany() returned %d entries, want 2: %v", storage, len(values), values)
+		}
+		if values["key1"].Name != "Alice" || values["key2"].Name != "Bob" {
+			t.Errorf("storage %v: GetMany() = %v", storage, values)
+		}
+
+		if values := cache.GetMany(); len(values) != 0 {
+			t.Errorf("storage %v: GetMany() with no keys = %v, want empty", storage, values)
+		}
+
+		tr.Close()
+	}
+}
+
+func TestCache_EvictLRU(t *testing.T) {
+	for _, storage := range []CacheStorage{CacheStorageHash, CacheStorageString} {
+		tr := NewTestRedis(t)
+
+		cache := NewCache[TestStruct]("testcache", CacheConfig{
+			Storage:    storage,
+			MaxEntries: 3,
+		}, tr.Redis)
+
+		for _, key := range []string{"key1", "key2", "key3"} {
+			cache.Set(key, TestStruct{Name: key}, time.Minute)
+			time.Sleep(time.Millisecond * 5)
+		}
+
+		// 读取 key1 后，最久未访问的是 key2
+		if _, ok := cache.Get("key1"); !ok {
+			t.Fatalf("storage %v: Get(key1) not found", storage)
+		}
+		time.Sleep(time.Millisecond * 5)
+		cache.Set("key4", TestStruct{Name: "key4"}, time.Minute)
+
+		if cache.Exists("key2") {
+			t.Errorf("storage %v: key2 should be evicted", storage)
+		}
+		for _, key := range []string{"key1", "key3", "key4"} {
+			if !cache.Exists(key) {
+				t.Errorf("storage %v: %s should not be evicted", storage, key)
+			}
+		}
+		if length := cache.Length(); length != 3 {
+			t.Errorf("storage %v: Length() = %d, want 3", storage, length)
+		}
+
+		// 批量写入同样受容量限制
+		cache.SetMany(map[string]TestStruct{
+			"key5": {Name: "key5"},
+			"key6": {Name: "key6"},
+		}, time.Minute)
+		if length := cache.Length(); length != 3 {
+			t.Errorf("storage %v: Length() after SetMany = %d, want 3", storage, length)
+		}
+		if evictions := cache.Stats().Evictions; evictions != 3 {
+			t.Errorf("storage %v: Stats().Evictions = %d, want 3", storage, evictions)
+		}
+
+		tr.Close()
+	}
+}
+
+func TestCache_EvictLFU(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{
+		MaxEntries:     2,
+		EvictionPolicy: CacheEvictLFU,
+	}, tr.Redis)
+
+	cache.Set("hot", TestStruct{Name: "hot"}, time.Minute)
+	cache.Set("cold", TestStruct{Name: "cold"}, time.Minute)
+	for i := 0; i < 3; i++ {
+		cache.Get("hot")
+	}
+	cache.GetMany("cold")
+
+	cache.Set("new", TestStruct{Name: "new"}, time.Minute)
+
+	if !cache.Exists("hot") {
+		t.Error("hot should not be evicted")
+	}
+	if cache.Exists("cold") {
+		t.Error("cold should be evicted")
+	}
+	// 刚写入的条目访问次数最少，但不会被同一次写入淘汰
+	if value, ok := cache.Get("new"); !ok || value.Name != "new" {
+		t.Errorf("Get(new) = %v, %v, want the value just written", value, ok)
+	}
+	if evictions := cache.Stats().Evictions; evictions != 1 {
+		t.Errorf("Stats().Evictions = %d, want 1", evictions)
+	}
+
+	// 删除的条目不再占用容量
+	cache.Delete("hot")
+	cache.Set("other", TestStruct{Name: "other"}, time.Minute)
+	if evictions := cache.Stats().Evictions; evictions != 1 {
+		t.Errorf("Stats().Evictions after Delete = %d, want 1", evictions)
+	}
+}
+
+func TestCache_EvictLocalHits(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{
+		MaxEntries:     2,
+		EvictionPolicy: CacheEvictLFU,
+		LocalSize:      10,
+	}, tr.Redis)
+	defer cache.Close()
+
+	cache.Set("a-hot", TestStruct{Name: "hot"}, time.Minute)
+	cache.Set("b-cold", TestStruct{Name: "cold"}, time.Minute)
+
+	// 第一次读取 Redis，之后命中本地缓存
+	for i := 0; i < 4; i++ {
+		cache.Get("a-hot")
+	}
+	cache.GetMany("b-cold")
+	cache.GetMany("b-cold")
+	cache.flushTouches()
+
+	cache.Set("new", TestStruct{Name: "new"}, time.Minute)
+
+	if !cache.Exists("a-hot") {
+		t.Error("a-hot read from the local tier should not be evicted")
+	}
+	if cache.Exists("b-cold") {
+		t.Error("b-cold should be evicted")
+	}
+}
+
+func TestCache_Stats(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{}, tr.Redis)
+
+	cache.Set("key1", TestStruct{Name: "Alice"}, time.Minute)
+	cache.SetMany(map[string]TestStruct{
+		"key2": {Name: "Bob"},
+		"key3": {Name: "Carol"},
+	}, time.Minute)
+	cache.Set("short", TestStruct{Name: "Short"}, time.Millisecond*50)
+
+	cache.Get("key1")
+	cache.Get("missing")
+	cache.GetMany("key2", "key3", "missing")
+	cache.Delete("key3")
+
+	cache.GetOrSet("loaded", func(key string) (TestStruct, time.Duration) {
+		time.Sleep(time.Millisecond * 10)
+		return TestStruct{Name: "Loaded"}, time.Minute
+	})
+	cache.GetOrLoad("failed", func(key string) (TestStruct, time.Duration, error) {
+		return TestStruct{}, 0, errors.New("backend down")
+	})
+
+	if tr.MiniRedis != nil {
+		tr.MiniRedis.FastForward(time.Millisecond * 100)
+	}
+	time.Sleep(time.Millisecond * 100)
+	cache.ClearExpired()
+
+	stats := cache.Stats()
+	want := CacheStats{
+		Hits:        3,
+		Misses:      4,
+		Sets:        5,
+		Deletes:     1,
+		Expirations: 1,
+		Loads:       2,
+		LoadErrors:  1,
+		LoadTime:    stats.LoadTime,
+	}
+	if stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+	if stats.LoadTime < time.Millisecond*10 {
+		t.Errorf("Stats().LoadTime = %v, want at least 10ms", stats.LoadTime)
+	}
+	if rate := stats.HitRate(); rate != 3.0/7 {
+		t.Errorf("HitRate() = %v, want %v", rate, 3.0/7)
+	}
+
+	// 未配置 SharedStats 时不写入 Redis
+	if global, err := cache.GlobalStats(); err != nil || global != (CacheStats{}) {
+		t.Errorf("GlobalStats() = %+v, %v, want empty", global, err)
+	}
+
+	cache.ResetStats()
+	if stats := cache.Stats(); stats != (CacheStats{}) {
+		t.Errorf("Stats() after ResetStats() = %+v, want empty", stats)
+	}
+}
+
+func TestCache_SharedStats(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	config := CacheConfig{SharedStats: true}
+	cache1 := NewCache[TestStruct]("testcache", config, tr.Redis)
+	cache2 := NewCache[TestStruct]("testcache", config, tr.Redis)
+	defer cache1.Close()
+	defer cache2.Close()
+
+	cache1.Set("key1", TestStruct{Name: "Alice"}, time.Minute)
+	cache1.Get("key1")
+	cache2.Get("key1")
+	cache2.Get("missing")
+	cache2.GetOrSet("loaded", func(key string) (TestStruct, time.Duration) {
+		return TestStruct{Name: "Loaded"}, time.Minute
+	})
+
+	if stats := cache1.Stats(); stats.Hits != 1 || stats.Misses != 0 {
+		t.Errorf("cache1.Stats() = %+v, want 1 hit", stats)
+	}
+
+	// GlobalStats 只写入本实例的增量，其他实例的增量需要等待后台写入
+	if err := cache2.FlushStats(); err != nil {
+		t.Fatalf("FlushStats() error = %v", err)
+	}
+	global, err := cache1.GlobalStats()
+	if err != nil {
+		t.Fatalf("GlobalStats() error = %v", err)
+	}
+	if global.Hits != 2 || global.Misses != 2 || global.Sets != 2 || global.Loads != 1 {
+		t.Errorf("GlobalStats() = %+v, want 2 hits, 2 misses, 2 sets, 1 load", global)
+	}
+
+	if err := cache2.ResetStats(); err != nil {
+		t.Fatalf("ResetStats() error = %v", err)
+	}
+	if global, _ := cache1.GlobalStats(); global != (CacheStats{}) {
+		t.Errorf("GlobalStats() after ResetStats() = %+v, want empty", global)
+	}
+}
+
+func TestCache_SharedStatsBatched(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{
+		SharedStats:        true,
+		StatsFlushInterval: time.Millisecond * 50,
+	}, tr.Redis)
+	defer cache.Close()
+
+	cache.Set("key1", TestStruct{Name: "Alice"}, time.Minute)
+	for i := 0; i < 10; i++ {
+		cache.Get("key1")
+	}
+
+	// 计数只累加在进程内，不逐次写入 Redis
+	if fields, _ := redis.Int(tr.Redis.Do("HLEN", cache.statsName())); fields != 0 {
+		t.Errorf("stats hash has %d fields before flush, want 0", fields)
+	}
+
+	time.Sleep(time.Millisecond * 150)
+	if hits, _ := redis.Int64(tr.Redis.Do("HGET", cache.statsName(), "hits")); hits != 10 {
+		t.Errorf("hits after periodic flush = %d, want 10", hits)
+	}
+
+	// Close 写入剩余的增量
+	cache.Get("missing")
+	cache.Close()
+	if misses, _ := redis.Int64(tr.Redis.Do("HGET", cache.statsName(), "misses")); misses != 1 {
+		t.Errorf("misses after Close = %d, want 1", misses)
+	}
+}
+
+func TestCache_LoadLockNoFence(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{}, tr.Redis)
+	for i := 0; i < 10; i++ {
+		cache.GetOrSet(fmt.Sprintf("key%d", i), func(key string) (TestStruct, time.Duration) {
+			return TestStruct{Name: key}, time.Minute
+		})
+	}
+
+	// 加载锁不分配防护令牌，不应在 Redis 中留下计数器
+	keys, err := redis.Strings(tr.Redis.Do("KEYS", "*:fence"))
+	if err != nil {
+		t.Fatalf("KEYS error = %v", err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("fence keys left after GetOrSet(): %v", keys)
+	}
+}
+
+func TestCache_EvictSetManyOverCapacity(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	cache := NewCache[TestStruct]("testcache", CacheConfig{
+		MaxEntries:     2,
+		EvictionPolicy: CacheEvictLFU,
+	}, tr.Redis)
+
+	cache.Set("old", TestStruct{Name: "old"}, time.Minute)
+	cache.SetMany(map[string]TestStruct{
+		"key1": {Name: "key1"},
+		"key2": {Name: "key2"},
+		"key3": {Name: "key3"},
+	}, time.Minute)
+
+	// 写入的条目超过容量时仍然不超过 MaxEntries，旧条目优先淘汰
+	if length := cache.Length(); length != 2 {
+		t.Errorf("Length() = %d, want 2", length)
+	}
+	if cache.Exists("old") {
+		t.Error("old should be evicted before the entries just written")
+	}
+	if evictions := cache.Stats().Evictions; evictions != 2 {
+		t.Errorf("Stats().Evictions = %d, want 2", evictions)
+	}
+}
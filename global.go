@@ -1,6 +1,9 @@
 package redisTool
 
-import "time"
+import (
+	"sync/atomic"
+	"time"
+)
 
 // 全局泛型函数，用于创建类型化的 Redis 数据结构
 // 这些函数可以使用默认连接或提供的连接
@@ -57,6 +60,20 @@ func NewTypeZSet[T any](name string, r ...*Redis) *RedisTypeZSet[T] {
 	}
 }
 
+// NewValue 创建类型化字符串键（全局函数）
+func NewValue[T any](name string, r ...*Redis) *RedisValue[T] {
+	var conn *Redis
+	if len(r) == 0 || r[0] == nil {
+		conn = defaultConnection
+	} else {
+		conn = r[0]
+	}
+	return &RedisValue[T]{
+		redis: conn,
+		name:  conn.CreateName(RedisTypeString, name),
+	}
+}
+
 // NewQueue 创建队列（全局函数）
 func NewQueue[T any](name string, config QueueConfig, r ...*Redis) *Queue[T] {
 	var conn *Redis
@@ -72,10 +89,31 @@ func NewQueue[T any](name string, config QueueConfig, r ...*Redis) *Queue[T] {
 		delayedName:    baseName + ":delayed",
 		processingName: baseName + ":processing",
 		retryName:      baseName + ":retry",
+		deadName:       baseName + ":dead",
+		priorityName:   baseName + ":priorities",
+		taskPriorities: baseName + ":task-priorities",
 		config:         config,
 	}
 }
 
+// NewStreamQueue 创建基于 Redis Streams 的队列（全局函数）
+func NewStreamQueue[T any](name string, config StreamQueueConfig, r ...*Redis) *StreamQueue[T] {
+	var conn *Redis
+	if len(r) == 0 || r[0] == nil {
+		conn = defaultConnection
+	} else {
+		conn = r[0]
+	}
+	streamName := conn.CreateName(RedisTypeStream_, name)
+	return &StreamQueue[T]{
+		redis:      conn,
+		name:       streamName,
+		deadName:   streamName + ":dead",
+		config:     newStreamQueueConfig(config),
+		groupReady: &atomic.Bool{},
+	}
+}
+
 // NewCache 创建缓存（全局函数）
 func NewCache[T any](name string, config CacheConfig, r ...*Redis) *Cache[T] {
 	var conn *Redis
@@ -85,11 +123,36 @@ func NewCache[T any](name string, config CacheConfig, r ...*Redis) *Cache[T] {
 		conn = r[0]
 	}
 	baseName := conn.CreateName(RedisTypeCache_, name)
-	return &Cache[T]{
-		redis:      conn,
-		dataName:   baseName + ":data",
-		expireName: baseName + ":expire",
-		config:     config,
+	cache := &Cache[T]{
+		redis:    conn,
+		name:     name,
+		baseName: baseName,
+		store:    newCacheStore(conn, baseName, config.Storage),
+		flight:   newFlightGroup[cacheResult[T]](),
+		loadCost: new(atomic.Int64),
+		counters: new(cacheCounters),
+		config:   newCacheConfig(config),
+	}
+	if config.LocalSize > 0 {
+		cache.startLocal()
+	}
+	if config.SharedStats {
+		cache.startStatsFlush()
+	}
+	return cache
+}
+
+// NewTopic 创建发布订阅主题（全局函数）
+func NewTopic[T any](name string, r ...*Redis) *Topic[T] {
+	var conn *Redis
+	if len(r) == 0 || r[0] == nil {
+		conn = defaultConnection
+	} else {
+		conn = r[0]
+	}
+	return &Topic[T]{
+		redis: conn,
+		name:  conn.CreateName(RedisTypeTopic_, name),
 	}
 }
 
@@ -99,6 +162,24 @@ func NewLock(name string, config ...LockConfig) *Lock {
 	return conn.NewLock(name, config...)
 }
 
+// NewReentrantLock 创建可重入分布式锁（全局函数）
+func NewReentrantLock(name, owner string, config ...LockConfig) *ReentrantLock {
+	conn := defaultConnection
+	return conn.NewReentrantLock(name, owner, config...)
+}
+
+// NewRWLock 创建分布式读写锁（全局函数）
+func NewRWLock(name string, config ...LockConfig) *RWLock {
+	conn := defaultConnection
+	return conn.NewRWLock(name, config...)
+}
+
+// NewSemaphore 创建分布式信号量（全局函数）
+func NewSemaphore(name string, permits int, config ...LockConfig) *Semaphore {
+	conn := defaultConnection
+	return conn.NewSemaphore(name, permits, config...)
+}
+
 // LastUseTime 获取上次使用时间（全局函数）
 func LastUseTime(key string, update bool) time.Time {
 	conn := defaultConnection
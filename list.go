@@ -1,12 +1,16 @@
 package redisTool
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
 	"github.com/gomodule/redigo/redis"
 )
 
+// pushManyBatchSize PushMany 单条 RPUSH 命令携带的最大元素数
+const pushManyBatchSize = 1000
+
 // RedisList Redis 列表
 type RedisList struct {
 	redis *Redis
@@ -26,6 +30,19 @@ func (r *Redis) NewList(name string) *RedisList {
 	}
 }
 
+// WithContext 返回绑定了上下文的列表视图
+func (l *RedisList) WithContext(ctx context.Context) *RedisList {
+	return &RedisList{
+		redis: l.redis.WithContext(ctx),
+		name:  l.name,
+	}
+}
+
+
+// Name 获取列表的 Redis 键名
+func (l *RedisList) Name() string {
+	return l.name
+}
 
 // Push 从右侧推入元素
 func (l *RedisList) Push(value interface{}) error {
@@ -37,6 +54,33 @@ func (l *RedisList) Push(value interface{}) error {
 	return err
 }
 
+// PushMany 从右侧批量推入元素，按批拆分为多条 RPUSH 并通过管道一次发送
+func (l *RedisList) PushMany(values ...interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	pipe := l.redis.Pipeline()
+	for start := 0; start < len(values); start += pushManyBatchSize {
+		end := start + pushManyBatchSize
+		if end > len(values) {
+			end = len(values)
+		}
+
+		args := make([]interface{}, 0, end-start+1)
+		args = append(args, l.name)
+		for _, value := range values[start:end] {
+			data, err := l.redis.Serialize(value)
+			if err != nil {
+				return err
+			}
+			args = append(args, data)
+		}
+		pipe.Send("RPUSH", args...)
+	}
+	return pipe.Exec()
+}
+
 // Pop 从右侧弹出元素
 func (l *RedisList) Pop() (interface{}, bool) {
 	data, err := redis.Bytes(l.redis.Do("RPOP", l.name))
@@ -178,6 +222,34 @@ func (l *RedisList) DeleteRange(start, end int) error {
 	return err
 }
 
+// SendPush 将从右侧推入元素的命令排入管道或事务
+func (l *RedisList) SendPush(s Sender, values ...interface{}) (*Result, error) {
+	args := make([]interface{}, 0, len(values)+1)
+	args = append(args, l.name)
+	for _, value := range values {
+		data, err := l.redis.Serialize(value)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, data)
+	}
+	return s.Send("RPUSH", args...), nil
+}
+
+// SendUnshift 将从左侧推入元素的命令排入管道或事务
+func (l *RedisList) SendUnshift(s Sender, values ...interface{}) (*Result, error) {
+	args := make([]interface{}, 0, len(values)+1)
+	args = append(args, l.name)
+	for _, value := range values {
+		data, err := l.redis.Serialize(value)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, data)
+	}
+	return s.Send("LPUSH", args...), nil
+}
+
 // Iterator 获取迭代器
 func (l *RedisList) Iterator(batchSize int) <-chan interface{} {
 	ch := make(chan interface{})
@@ -235,11 +307,32 @@ func (l *RedisList) SafeUpset(index int, value interface{}) (interface{}, bool,
 
 // === RedisTypeList 类型化方法 ===
 
+// WithContext 返回绑定了上下文的列表视图
+func (tl *RedisTypeList[T]) WithContext(ctx context.Context) *RedisTypeList[T] {
+	return &RedisTypeList[T]{
+		list: tl.list.WithContext(ctx),
+	}
+}
+
+// Name 获取列表的 Redis 键名
+func (tl *RedisTypeList[T]) Name() string {
+	return tl.list.Name()
+}
+
 // Push 推入元素
 func (tl *RedisTypeList[T]) Push(value T) error {
 	return tl.list.Push(value)
 }
 
+// PushMany 批量推入元素
+func (tl *RedisTypeList[T]) PushMany(values ...T) error {
+	interfaces := make([]interface{}, len(values))
+	for i, v := range values {
+		interfaces[i] = v
+	}
+	return tl.list.PushMany(interfaces...)
+}
+
 // Pop 弹出元素
 func (tl *RedisTypeList[T]) Pop() (T, bool) {
 	var zero T
@@ -375,6 +468,24 @@ func (tl *RedisTypeList[T]) DeleteRange(start, end int) error {
 	return tl.list.DeleteRange(start, end)
 }
 
+// SendPush 将从右侧推入元素的命令排入管道或事务
+func (tl *RedisTypeList[T]) SendPush(s Sender, values ...T) (*Result, error) {
+	interfaces := make([]interface{}, len(values))
+	for i, v := range values {
+		interfaces[i] = v
+	}
+	return tl.list.SendPush(s, interfaces...)
+}
+
+// SendUnshift 将从左侧推入元素的命令排入管道或事务
+func (tl *RedisTypeList[T]) SendUnshift(s Sender, values ...T) (*Result, error) {
+	interfaces := make([]interface{}, len(values))
+	for i, v := range values {
+		interfaces[i] = v
+	}
+	return tl.list.SendUnshift(s, interfaces...)
+}
+
 // Iterator 获取迭代器
 func (tl *RedisTypeList[T]) Iterator(batchSize int) <-chan T {
 	ch := make(chan T)
@@ -1,6 +1,7 @@
 package redisTool
 
 import (
+	"context"
 	"testing"
 )
 
@@ -498,3 +499,61 @@ func TestRedisTypeList_Clear(t *testing.T) {
 		t.Error("List should be empty after Clear()")
 	}
 }
+
+func TestRedisTypeList_WithContext(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	typeList := NewTypeList[TestStruct]("testlist", tr.Redis)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctxList := typeList.WithContext(ctx)
+
+	if err := ctxList.Push(TestStruct{Name: "Alice", Age: 30}); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if typeList.Length() != 1 {
+		t.Errorf("Length() = %v, want 1", typeList.Length())
+	}
+
+	cancel()
+
+	if err := ctxList.Push(TestStruct{Name: "Bob", Age: 25}); err == nil {
+		t.Error("Push() with cancelled context should return error")
+	}
+	if _, ok := ctxList.Pop(); ok {
+		t.Error("Pop() with cancelled context should return false")
+	}
+	if typeList.Length() != 1 {
+		t.Errorf("Length() after cancelled Push = %v, want 1", typeList.Length())
+	}
+}
+
+func TestRedisTypeList_PushMany(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	typeList := NewTypeList[int]("testlist", tr.Redis)
+
+	values := make([]int, pushManyBatchSize+10)
+	for i := range values {
+		values[i] = i
+	}
+
+	if err := typeList.PushMany(values...); err != nil {
+		t.Fatalf("PushMany() error = %v", err)
+	}
+
+	if typeList.Length() != len(values) {
+		t.Errorf("Length() = %v, want %v", typeList.Length(), len(values))
+	}
+
+	// 顺序保持不变
+	got, err := typeList.Get(pushManyBatchSize-1, pushManyBatchSize)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != pushManyBatchSize-1 || got[1] != pushManyBatchSize {
+		t.Errorf("Get() across batch boundary = %v", got)
+	}
+}
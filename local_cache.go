@@ -0,0 +1,117 @@
+package redisTool
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// localCacheExpire 本地缓存条目的默认最长存活时间
+const localCacheExpire = time.Minute
+
+// localCache 进程内的 LRU 缓存，按条目数量和存活时间淘汰
+type localCache[T any] struct {
+	mu      sync.Mutex
+	size    int
+	expire  time.Duration
+	order   *list.List // 最近使用的条目在前
+	entries map[string]*list.Element
+}
+
+// localEntry 本地缓存条目
+type localEntry[T any] struct {
+	key      string
+	value    T
+	expireAt time.Time
+}
+
+// newLocalCache 创建本地缓存
+func newLocalCache[T any](size int, expire time.Duration) *localCache[T] {
+	if expire <= 0 {
+		expire = localCacheExpire
+	}
+	return &localCache[T]{
+		size:    size,
+		expire:  expire,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get 获取条目，过期的条目会被删除
+func (l *localCache[T]) get(key string) (T, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var zero T
+	elem, ok := l.entries[key]
+	if !ok {
+		return zero, false
+	}
+
+	entry := elem.Value.(*localEntry[T])
+	if time.Now().After(entry.expireAt) {
+		l.removeElement(elem)
+		return zero, false
+	}
+
+	l.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// set 设置条目，ttl 不超过本地缓存的最长存活时间；超过容量时淘汰最久未使用的条目
+func (l *localCache[T]) set(key string, value T, ttl time.Duration) {
+	if ttl <= 0 || ttl > l.expire {
+		ttl = l.expire
+	}
+	expireAt := time.Now().Add(ttl)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[key]; ok {
+		entry := elem.Value.(*localEntry[T])
+		entry.value, entry.expireAt = value, expireAt
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&localEntry[T]{key: key, value: value, expireAt: expireAt})
+	for l.order.Len() > l.size {
+		l.removeElement(l.order.Back())
+	}
+}
+
+// remove 删除条目
+func (l *localCache[T]) remove(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := l.entries[key]; ok {
+			l.removeElement(elem)
+		}
+	}
+}
+
+// purge 清空所有条目
+func (l *localCache[T]) purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.order.Init()
+	l.entries = make(map[string]*list.Element)
+}
+
+// len 获取条目数量（包括尚未清理的过期条目）
+func (l *localCache[T]) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+// removeElement 删除链表节点，调用方需持有锁
+func (l *localCache[T]) removeElement(elem *list.Element) {
+	l.order.Remove(elem)
+	delete(l.entries, elem.Value.(*localEntry[T]).key)
+}
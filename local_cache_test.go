@@ -0,0 +1,57 @@
+package redisTool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocalCache_LRU(t *testing.T) {
+	local := newLocalCache[int](2, time.Minute)
+
+	local.set("a", 1, 0)
+	local.set("b", 2, 0)
+	local.get("a") // a 最近使用过，b 会被淘汰
+	local.set("c", 3, 0)
+
+	if _, ok := local.get("b"); ok {
+		t.Error("get(b) should miss after eviction")
+	}
+	if value, ok := local.get("a"); !ok || value != 1 {
+		t.Errorf("get(a) = %v, %v, want 1", value, ok)
+	}
+	if value, ok := local.get("c"); !ok || value != 3 {
+		t.Errorf("get(c) = %v, %v, want 3", value, ok)
+	}
+	if local.len() != 2 {
+		t.Errorf("len() = %v, want 2", local.len())
+	}
+
+	local.remove("a")
+	if _, ok := local.get("a"); ok {
+		t.Error("get(a) should miss after remove")
+	}
+	local.purge()
+	if local.len() != 0 {
+		t.Errorf("len() after purge = %v, want 0", local.len())
+	}
+}
+
+func TestLocalCache_Expire(t *testing.T) {
+	local := newLocalCache[int](10, time.Millisecond*50)
+
+	local.set("short", 1, time.Millisecond*10)
+	local.set("capped", 2, time.Hour)
+
+	time.Sleep(time.Millisecond * 20)
+	if _, ok := local.get("short"); ok {
+		t.Error("get(short) should miss after its ttl")
+	}
+	if _, ok := local.get("capped"); !ok {
+		t.Error("get(capped) should hit before local expire")
+	}
+
+	time.Sleep(time.Millisecond * 40)
+	if _, ok := local.get("capped"); ok {
+		t.Error("get(capped) should miss after local expire")
+	}
+}
@@ -1,6 +1,8 @@
 package redisTool
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -8,17 +10,41 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrLockLost 锁已丢失（过期或被其他持有者获取），LockCtx 返回的上下文以此为取消原因
+var ErrLockLost = errors.New("redisTool: lock lost")
+
+// lockNowScript Lua 脚本中以 Redis 服务器时间计算当前毫秒时间戳 now
+// 租约到期时间都以服务器时间计算，客户端之间的时钟偏差不会让某个客户端提前清理他人的租约
+const lockNowScript = `
+	redis.replicate_commands()
+	local serverTime = redis.call("TIME")
+	local now = tonumber(serverTime[1]) * 1000 + math.floor(tonumber(serverTime[2]) / 1000)
+`
+
 // Lock 分布式锁
 type Lock struct {
-	redis  *Redis
-	name   string
-	token  string
-	config LockConfig
-	locked bool
+	redis     *Redis
+	name      string
+	token     string
+	config    LockConfig
+	locked    bool
+	fence     int64                   // 本次持有锁获得的防护令牌
+	stopWatch context.CancelCauseFunc // LockCtx 启动的续期协程
 }
 
 // NewLock 创建分布式锁
 func (r *Redis) NewLock(name string, config ...LockConfig) *Lock {
+	return &Lock{
+		redis:  r,
+		name:   r.CreateName(RedisTypeLock_, name),
+		token:  uuid.New().String(),
+		config: newLockConfig(config...),
+		locked: false,
+	}
+}
+
+// newLockConfig 合并锁配置与默认值
+func newLockConfig(config ...LockConfig) LockConfig {
 	cfg := LockConfig{
 		WaitTime:           time.Second * 5,
 		RetryTime:          time.Second,
@@ -35,50 +61,117 @@ func (r *Redis) NewLock(name string, config ...LockConfig) *Lock {
 		if config[0].MaxGetLockWaitTime >= 0 {
 			cfg.MaxGetLockWaitTime = config[0].MaxGetLockWaitTime
 		}
+		cfg.DisableFencing = config[0].DisableFencing
 	}
-	
+	return cfg
+}
+
+// WithContext 返回绑定了上下文的锁视图，与原锁共享同一个 token
+// 在视图上获取的锁需要通过同一个视图释放；等待获取锁时上下文被取消会立即返回错误
+func (l *Lock) WithContext(ctx context.Context) *Lock {
 	return &Lock{
-		redis:  r,
-		name:   r.CreateName(RedisTypeLock_, name),
-		token:  uuid.New().String(),
-		config: cfg,
-		locked: false,
+		redis:  l.redis.WithContext(ctx),
+		name:   l.name,
+		token:  l.token,
+		config: l.config,
+		locked: l.locked,
+		fence:  l.fence,
 	}
 }
 
 // Lock 获取锁
 func (l *Lock) Lock() error {
+	if err := acquireWithRetry(l.redis.Context(), l.config, func() bool {
+		acquired, err := l.tryAcquire()
+		return err == nil && acquired
+	}); err != nil {
+		return err
+	}
+	l.locked = true
+	return nil
+}
+
+// acquireWithRetry 按 RetryTime 重复调用 try 直到成功，超过 MaxGetLockWaitTime 或 ctx 取消时返回错误
+// MaxGetLockWaitTime 为 0 时只尝试一次
+func acquireWithRetry(ctx context.Context, config LockConfig, try func() bool) error {
 	startTime := time.Now()
 	
 	for {
 		// 尝试获取锁
-		if l.tryAcquire() {
-			l.locked = true
+		if try() {
 			return nil
 		}
 		
 		// 检查是否超时
-		if l.config.MaxGetLockWaitTime > 0 && time.Since(startTime) >= l.config.MaxGetLockWaitTime {
-			return fmt.Errorf("lock timeout: failed to acquire lock within %v", l.config.MaxGetLockWaitTime)
+		if config.MaxGetLockWaitTime > 0 && time.Since(startTime) >= config.MaxGetLockWaitTime {
+			return fmt.Errorf("lock timeout: failed to acquire lock within %v", config.MaxGetLockWaitTime)
 		}
 		
 		// 如果 MaxGetLockWaitTime 为 0，立即返回
-		if l.config.MaxGetLockWaitTime == 0 {
+		if config.MaxGetLockWaitTime == 0 {
 			return fmt.Errorf("lock failed: unable to acquire lock")
 		}
 		
 		// 等待后重试
-		time.Sleep(l.config.RetryTime)
+		if err := sleepContext(ctx, config.RetryTime); err != nil {
+			return err
+		}
+	}
+}
+
+// LockCtx 获取锁并在后台自动续期，返回的上下文在锁丢失时取消
+// 续期失败或锁已不归自己持有时，返回的上下文立即取消，context.Cause 返回包装了 ErrLockLost 的错误；
+// ctx 取消或调用 Unlock 时停止续期，Unlock 仍需调用方执行；等待获取锁时 ctx 被取消会立即返回错误
+func (l *Lock) LockCtx(ctx context.Context) (context.Context, error) {
+	view := l.WithContext(ctx)
+	if err := view.Lock(); err != nil {
+		return nil, err
+	}
+	l.locked, l.fence = true, view.fence
+
+	lockCtx, cancel := context.WithCancelCause(ctx)
+	l.stopWatch = cancel
+	go l.watch(lockCtx, cancel)
+	return lockCtx, nil
+}
+
+// watch 每隔 WaitTime/3 续期一次，续期失败时以 ErrLockLost 取消上下文
+func (l *Lock) watch(ctx context.Context, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(l.config.WaitTime / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			held, err := l.renew()
+			if err != nil {
+				cancel(fmt.Errorf("%w: %v", ErrLockLost, err))
+				return
+			}
+			if !held {
+				cancel(ErrLockLost)
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
 // TryLock 尝试获取锁
 func (l *Lock) TryLock() bool {
-	if l.tryAcquire() {
-		l.locked = true
-		return true
+	acquired, err := l.tryLock()
+	return err == nil && acquired
+}
+
+// tryLock 尝试获取锁，与 TryLock 不同的是区分锁被占用（false, nil）和 Redis 出错
+func (l *Lock) tryLock() (bool, error) {
+	acquired, err := l.tryAcquire()
+	if err != nil || !acquired {
+		return false, err
 	}
-	return false
+	l.locked = true
+	return true, nil
 }
 
 // Unlock 释放锁
@@ -86,7 +179,26 @@ func (l *Lock) Unlock() error {
 	if !l.locked {
 		return nil
 	}
+	if l.stopWatch != nil {
+		l.stopWatch(nil)
+		l.stopWatch = nil
+	}
+	
+	released, err := l.release()
+	if err != nil {
+		return err
+	}
+	
+	if released {
+		l.locked, l.fence = false, 0
+		return nil
+	}
 	
+	return fmt.Errorf("unlock failed: lock not held by this instance")
+}
+
+// release 删除自己持有的锁，不修改本地状态，返回是否删除
+func (l *Lock) release() (bool, error) {
 	// 使用 Lua 脚本确保只删除自己持有的锁
 	script := `
 		if redis.call("GET", KEYS[1]) == ARGV[1] then
@@ -102,15 +214,9 @@ func (l *Lock) Unlock() error {
 	luaScript := redis.NewScript(1, script)
 	result, err := redis.Int(luaScript.Do(conn, l.name, l.token))
 	if err != nil {
-		return err
-	}
-	
-	if result == 1 {
-		l.locked = false
-		return nil
+		return false, err
 	}
-	
-	return fmt.Errorf("unlock failed: lock not held by this instance")
+	return result == 1, nil
 }
 
 // LockFunc 使用闭包简化锁的使用
@@ -140,12 +246,37 @@ func (l *Lock) IsLocked() bool {
 	return l.locked
 }
 
+// Token 获取本次持有锁的防护令牌（fencing token），未持有锁或配置了 DisableFencing 时返回 0
+// 令牌在每次成功获取同名锁时单调递增，下游存储可以记录见过的最大令牌并拒绝更小令牌的写入，
+// 防止锁过期后仍在运行的旧持有者覆盖新持有者的数据
+func (l *Lock) Token() int64 {
+	if !l.locked {
+		return 0
+	}
+	return l.fence
+}
+
 // Refresh 刷新锁的过期时间
 func (l *Lock) Refresh() error {
 	if !l.locked {
 		return fmt.Errorf("lock not held")
 	}
 	
+	held, err := l.renew()
+	if err != nil {
+		return err
+	}
+	
+	if !held {
+		l.locked = false
+		return fmt.Errorf("refresh failed: lock not held by this instance")
+	}
+	
+	return nil
+}
+
+// renew 延长自己持有的锁的过期时间，不修改本地状态，返回锁是否仍由自己持有
+func (l *Lock) renew() (bool, error) {
 	// 使用 Lua 脚本确保只刷新自己持有的锁
 	script := `
 		if redis.call("GET", KEYS[1]) == ARGV[1] then
@@ -161,32 +292,63 @@ func (l *Lock) Refresh() error {
 	luaScript := redis.NewScript(1, script)
 	result, err := redis.Int(luaScript.Do(conn, l.name, l.token, int(l.config.WaitTime.Milliseconds())))
 	if err != nil {
-		return err
-	}
-	
-	if result == 0 {
-		l.locked = false
-		return fmt.Errorf("refresh failed: lock not held by this instance")
+		return false, err
 	}
-	
-	return nil
+	return result == 1, nil
 }
 
-// tryAcquire 尝试获取锁
-func (l *Lock) tryAcquire() bool {
+// tryAcquire 尝试获取锁，未配置 DisableFencing 时同时递增防护令牌，不修改持有状态
+func (l *Lock) tryAcquire() (bool, error) {
+	if l.config.DisableFencing {
+		// 使用 SET NX PX 命令原子性地设置锁
+		result, err := l.redis.Do("SET", l.name, l.token, "NX", "PX", int(l.config.WaitTime.Milliseconds()))
+		if err != nil {
+			return false, err
+		}
+		return result != nil, nil
+	}
+	
+	// 使用 SET NX PX 原子性地设置锁，获取成功后在同一个脚本中递增令牌
+	script := `
+		if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+			return redis.call("INCR", KEYS[2])
+		end
+		return 0
+	`
+	
 	conn := l.redis.GetConn()
 	defer conn.Close()
 	
-	// 使用 SET NX PX 命令原子性地设置锁
-	result, err := redis.String(conn.Do("SET", l.name, l.token, "NX", "PX", int(l.config.WaitTime.Milliseconds())))
-	if err != nil || result != "OK" {
-		return false
+	luaScript := redis.NewScript(2, script)
+	fence, err := redis.Int64(luaScript.Do(conn, l.name, l.fenceName(), l.token, int(l.config.WaitTime.Milliseconds())))
+	if err != nil || fence == 0 {
+		return false, err
 	}
 	
-	return true
+	l.fence = fence
+	return true, nil
+}
+
+// fenceName 防护令牌计数器，不设置过期时间以保证令牌单调递增
+func (l *Lock) fenceName() string {
+	return l.name + ":fence"
+}
+
+// sleepContext 等待指定时间，上下文被取消时提前返回错误
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // StartRefreshLoop 启动自动刷新锁的循环
+// 刷新失败时循环直接退出，调用方无法得知锁已丢失；需要感知锁丢失时使用 LockCtx
 func (l *Lock) StartRefreshLoop() chan struct{} {
 	stopCh := make(chan struct{})
 	
@@ -1,6 +1,8 @@
 package redisTool
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -420,3 +422,191 @@ func TestLock_AutoExpire(t *testing.T) {
 	}
 	lock2.Unlock()
 }
+
+func TestLock_WithContextCancel(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	lock1 := tr.Redis.NewLock("testlock")
+	if err := lock1.Lock(); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	defer lock1.Unlock()
+
+	lock2 := tr.Redis.NewLock("testlock", LockConfig{
+		WaitTime:           time.Second * 5,
+		RetryTime:          time.Millisecond * 50,
+		MaxGetLockWaitTime: time.Second * 10,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+
+	start := time.Now()
+	err := lock2.WithContext(ctx).Lock()
+	if err == nil {
+		t.Fatal("Lock() should fail when context is done")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Lock() returned after %v, want it to stop with the context", time.Since(start))
+	}
+}
+
+func TestLock_LockCtx(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	lock := tr.Redis.NewLock("testlock", LockConfig{
+		WaitTime: time.Millisecond * 300,
+	})
+
+	ctx, err := lock.LockCtx(context.Background())
+	if err != nil {
+		t.Fatalf("LockCtx() error = %v", err)
+	}
+
+	// 续期协程保持锁有效
+	time.Sleep(time.Millisecond * 500)
+	if ctx.Err() != nil {
+		t.Fatalf("context canceled while lock held: %v", context.Cause(ctx))
+	}
+	if ttl, _ := tr.Redis.Do("PTTL", lock.name); ttl.(int64) <= 0 {
+		t.Errorf("PTTL = %v, want positive", ttl)
+	}
+	if tr.Redis.NewLock("testlock").TryLock() {
+		t.Error("TryLock() by another instance should fail")
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if !errors.Is(context.Cause(ctx), context.Canceled) {
+		t.Errorf("context.Cause() after Unlock() = %v, want context.Canceled", context.Cause(ctx))
+	}
+}
+
+func TestLock_LockCtxLost(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	lock := tr.Redis.NewLock("testlock", LockConfig{
+		WaitTime: time.Millisecond * 300,
+	})
+
+	ctx, err := lock.LockCtx(context.Background())
+	if err != nil {
+		t.Fatalf("LockCtx() error = %v", err)
+	}
+
+	// 模拟锁过期后被其他持有者获取
+	tr.Redis.Do("SET", lock.name, "other")
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context should be canceled after the lock is lost")
+	}
+	if !errors.Is(context.Cause(ctx), ErrLockLost) {
+		t.Errorf("context.Cause() = %v, want ErrLockLost", context.Cause(ctx))
+	}
+	if err := lock.Unlock(); err == nil {
+		t.Error("Unlock() after the lock is lost should fail")
+	}
+}
+
+func TestLock_LockCtxParentCanceled(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	holder := tr.Redis.NewLock("testlock")
+	holder.Lock()
+	defer holder.Unlock()
+
+	lock := tr.Redis.NewLock("testlock", LockConfig{
+		RetryTime:          time.Millisecond * 50,
+		MaxGetLockWaitTime: time.Second * 10,
+	})
+
+	parent, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := lock.LockCtx(parent); err == nil {
+		t.Fatal("LockCtx() should fail when context is done")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("LockCtx() returned after %v, want it to stop with the context", time.Since(start))
+	}
+	if lock.IsLocked() {
+		t.Error("IsLocked() = true after failed LockCtx()")
+	}
+}
+
+func TestLock_Token(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	lock1 := tr.Redis.NewLock("testlock", LockConfig{MaxGetLockWaitTime: 0})
+	lock2 := tr.Redis.NewLock("testlock", LockConfig{MaxGetLockWaitTime: 0})
+
+	if token := lock1.Token(); token != 0 {
+		t.Errorf("Token() before Lock() = %d, want 0", token)
+	}
+
+	if err := lock1.Lock(); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	first := lock1.Token()
+	if first <= 0 {
+		t.Errorf("Token() = %d, want positive", first)
+	}
+
+	// 获取失败不消耗令牌
+	if lock2.TryLock() {
+		t.Fatal("TryLock() should fail while lock1 holds the lock")
+	}
+	if token := lock2.Token(); token != 0 {
+		t.Errorf("Token() after failed TryLock() = %d, want 0", token)
+	}
+
+	lock1.Unlock()
+	if token := lock1.Token(); token != 0 {
+		t.Errorf("Token() after Unlock() = %d, want 0", token)
+	}
+
+	if !lock2.TryLock() {
+		t.Fatal("TryLock() after Unlock() should succeed")
+	}
+	if second := lock2.Token(); second != first+1 {
+		t.Errorf("Token() = %d, want %d", second, first+1)
+	}
+	lock2.Unlock()
+
+	// 通过 LockCtx 获取时同样分配令牌
+	ctx, err := lock1.LockCtx(context.Background())
+	if err != nil || ctx == nil {
+		t.Fatalf("LockCtx() error = %v", err)
+	}
+	if third := lock1.Token(); third != first+2 {
+		t.Errorf("Token() after LockCtx() = %d, want %d", third, first+2)
+	}
+	lock1.Unlock()
+}
+
+func TestLock_TokenDisabled(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	lock := tr.Redis.NewLock("testlock", LockConfig{DisableFencing: true})
+	if err := lock.Lock(); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	defer lock.Unlock()
+
+	if token := lock.Token(); token != 0 {
+		t.Errorf("Token() with DisableFencing = %d, want 0", token)
+	}
+	if exists, _ := tr.Redis.Do("EXISTS", lock.fenceName()); exists.(int64) != 0 {
+		t.Error("fence key should not be created with DisableFencing")
+	}
+}
@@ -1,6 +1,7 @@
 package redisTool
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
@@ -38,6 +39,19 @@ func (r *Redis) NewNumberMap(name string) *RedisNumberMap {
 	}
 }
 
+// WithContext 返回绑定了上下文的哈希表视图
+func (m *RedisMap) WithContext(ctx context.Context) *RedisMap {
+	return &RedisMap{
+		redis: m.redis.WithContext(ctx),
+		name:  m.name,
+	}
+}
+
+// Name 获取哈希表的 Redis 键名
+func (m *RedisMap) Name() string {
+	return m.name
+}
+
 // Set 设置键值
 func (m *RedisMap) Set(key string, value interface{}) error {
 	data, err := m.redis.Serialize(value)
@@ -140,6 +154,72 @@ func (m *RedisMap) Keys() ([]string, error) {
 	return keys, nil
 }
 
+// SetMany 批量设置键值，所有命令通过管道一次发送
+func (m *RedisMap) SetMany(values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	pipe := m.redis.Pipeline()
+	for key, value := range values {
+		data, err := m.redis.Serialize(value)
+		if err != nil {
+			return err
+		}
+		pipe.Send("HSET", m.name, key, data)
+	}
+	return pipe.Exec()
+}
+
+// GetMany 批量获取值，只返回存在的键，所有命令通过管道一次发送
+func (m *RedisMap) GetMany(keys ...string) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	pipe := m.redis.Pipeline()
+	replies := make([]*Result, len(keys))
+	for i, key := range keys {
+		replies[i] = pipe.Send("HGET", m.name, key)
+	}
+	if err := pipe.Exec(); err != nil {
+		return nil, err
+	}
+
+	for i, key := range keys {
+		data, err := replies[i].Bytes()
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		var value interface{}
+		if err := m.redis.Deserialize(data, &value); err != nil {
+			continue
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// SendSet 将设置键值的命令排入管道或事务
+func (m *RedisMap) SendSet(s Sender, key string, value interface{}) (*Result, error) {
+	data, err := m.redis.Serialize(value)
+	if err != nil {
+		return nil, err
+	}
+	return s.Send("HSET", m.name, key, data), nil
+}
+
+// SendDelete 将删除键的命令排入管道或事务
+func (m *RedisMap) SendDelete(s Sender, keys ...string) *Result {
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, m.name)
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	return s.Send("HDEL", args...)
+}
+
 // Iterator 获取迭代器
 func (m *RedisMap) Iterator(batchSize int) <-chan struct {
 	Key   string
@@ -190,6 +270,18 @@ func (m *RedisMap) Iterator(batchSize int) <-chan struct {
 
 // === RedisTypeMap 类型化方法 ===
 
+// WithContext 返回绑定了上下文的哈希表视图
+func (tm *RedisTypeMap[T]) WithContext(ctx context.Context) *RedisTypeMap[T] {
+	return &RedisTypeMap[T]{
+		rmap: tm.rmap.WithContext(ctx),
+	}
+}
+
+// Name 获取哈希表的 Redis 键名
+func (tm *RedisTypeMap[T]) Name() string {
+	return tm.rmap.Name()
+}
+
 // Set 设置键值
 func (tm *RedisTypeMap[T]) Set(key string, value T) error {
 	return tm.rmap.Set(key, value)
@@ -268,6 +360,48 @@ func (tm *RedisTypeMap[T]) ToArray() (map[string]T, error) {
 	return result, nil
 }
 
+// SetMany 批量设置键值
+func (tm *RedisTypeMap[T]) SetMany(values map[string]T) error {
+	interfaces := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		interfaces[key] = value
+	}
+	return tm.rmap.SetMany(interfaces)
+}
+
+// GetMany 批量获取值，只返回存在的键
+func (tm *RedisTypeMap[T]) GetMany(keys ...string) (map[string]T, error) {
+	values, err := tm.rmap.GetMany(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	var zero T
+	result := make(map[string]T, len(values))
+	for key, value := range values {
+		if v, ok := value.(T); ok {
+			result[key] = v
+		} else {
+			item := reflect.New(reflect.TypeOf(zero)).Interface()
+			data, _ := tm.rmap.redis.Serialize(value)
+			if err := tm.rmap.redis.Deserialize(data, item); err == nil {
+				result[key] = reflect.ValueOf(item).Elem().Interface().(T)
+			}
+		}
+	}
+	return result, nil
+}
+
+// SendSet 将设置键值的命令排入管道或事务
+func (tm *RedisTypeMap[T]) SendSet(s Sender, key string, value T) (*Result, error) {
+	return tm.rmap.SendSet(s, key, value)
+}
+
+// SendDelete 将删除键的命令排入管道或事务
+func (tm *RedisTypeMap[T]) SendDelete(s Sender, keys ...string) *Result {
+	return tm.rmap.SendDelete(s, keys...)
+}
+
 // Keys 获取所有键
 func (tm *RedisTypeMap[T]) Keys() ([]string, error) {
 	return tm.rmap.Keys()
@@ -309,6 +443,18 @@ func (tm *RedisTypeMap[T]) Iterator(batchSize int) <-chan struct {
 
 // === RedisNumberMap 数字型方法 ===
 
+// WithContext 返回绑定了上下文的哈希表视图
+func (nm *RedisNumberMap) WithContext(ctx context.Context) *RedisNumberMap {
+	return &RedisNumberMap{
+		rmap: nm.rmap.WithContext(ctx),
+	}
+}
+
+// Name 获取哈希表的 Redis 键名
+func (nm *RedisNumberMap) Name() string {
+	return nm.rmap.Name()
+}
+
 // Set 设置数值
 func (nm *RedisNumberMap) Set(key string, value float64) error {
 	_, err := nm.rmap.redis.Do("HSET", nm.rmap.name, key, value)
@@ -333,6 +479,11 @@ func (nm *RedisNumberMap) Increment(key string, delta float64) (float64, error)
 	return value, nil
 }
 
+// SendIncrement 将增加数值的命令排入管道或事务
+func (nm *RedisNumberMap) SendIncrement(s Sender, key string, delta float64) *Result {
+	return s.Send("HINCRBYFLOAT", nm.rmap.name, key, delta)
+}
+
 // Decrement 减少数值
 func (nm *RedisNumberMap) Decrement(key string, delta float64) (float64, error) {
 	return nm.Increment(key, -delta)
@@ -491,3 +491,36 @@ func TestRedisNumberMap_Operations(t *testing.T) {
 		t.Error("Map should be empty after Clear()")
 	}
 }
+
+func TestRedisTypeMap_SetManyGetMany(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	typeMap := NewTypeMap[TestStruct]("testmap", tr.Redis)
+
+	err := typeMap.SetMany(map[string]TestStruct{
+		"user1": {Name: "Alice", Age: 30},
+		"user2": {Name: "Bob", Age: 25},
+	})
+	if err != nil {
+		t.Fatalf("SetMany() error = %v", err)
+	}
+
+	if typeMap.Length() != 2 {
+		t.Errorf("Length() = %v, want 2", typeMap.Length())
+	}
+
+	values, err := typeMap.GetMany("user1", "user2", "missing")
+	if err != nil {
+		t.Fatalf("GetMany() error = %v", err)
+	}
+	if len(values) != 2 {
+		t.Errorf("GetMany() returned %v items, want 2", len(values))
+	}
+	if values["user1"].Name != "Alice" || values["user2"].Name != "Bob" {
+		t.Errorf("GetMany() = %v", values)
+	}
+	if _, ok := values["missing"]; ok {
+		t.Error("GetMany() should not return missing keys")
+	}
+}
@@ -0,0 +1,205 @@
+package redisTool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// multiLockDriftFactor 时钟漂移系数，有效期扣除 WaitTime 的这一比例再加 2 毫秒
+const multiLockDriftFactor = 0.01
+
+// multiLockNodeTimeoutFactor 单个节点操作的超时时间占 WaitTime 的比例，避免一个无响应的节点耗尽有效期
+const multiLockNodeTimeoutFactor = 0.05
+
+// MultiLock 基于多个独立 Redis 节点的分布式锁（Redlock）
+// 在超过半数的节点上获取成功，且扣除获取耗时和时钟漂移后仍在有效期内，才视为获取成功；
+// 获取失败时释放所有节点上的锁。单个节点故障或主从切换不会产生两个持有者
+type MultiLock struct {
+	locks      []*Lock // 每个节点上的锁，共享同一个 token
+	config     LockConfig
+	locked     bool
+	validUntil time.Time
+}
+
+// NewMultiLock 创建基于多个独立 Redis 节点的分布式锁，节点之间不能是主从关系
+// WaitTime 为锁的有效期，单个节点的操作超过 WaitTime 的 5% 视为失败；RetryTime 和 MaxGetLockWaitTime 控制 Lock 的重试和最长等待时间
+func NewMultiLock(name string, nodes []*Redis, config ...LockConfig) *MultiLock {
+	cfg := newLockConfig(config...)
+	token := uuid.New().String()
+
+	// 各节点上的令牌互不相关，不分配防护令牌
+	nodeConfig := cfg
+	nodeConfig.DisableFencing = true
+
+	locks := make([]*Lock, len(nodes))
+	for i, node := range nodes {
+		locks[i] = node.NewLock(name, nodeConfig)
+		locks[i].token = token
+	}
+	return &MultiLock{
+		locks:  locks,
+		config: cfg,
+	}
+}
+
+// WithContext 返回绑定了上下文的锁视图，与原锁共享同一个 token
+// 在视图上获取的锁需要通过同一个视图释放；等待获取锁时上下文被取消会立即返回错误
+func (m *MultiLock) WithContext(ctx context.Context) *MultiLock {
+	locks := make([]*Lock, len(m.locks))
+	for i, lock := range m.locks {
+		locks[i] = lock.WithContext(ctx)
+	}
+	return &MultiLock{
+		locks:      locks,
+		config:     m.config,
+		locked:     m.locked,
+		validUntil: m.validUntil,
+	}
+}
+
+// Lock 获取锁
+func (m *MultiLock) Lock() error {
+	ctx := context.Background()
+	if len(m.locks) > 0 {
+		ctx = m.locks[0].redis.Context()
+	}
+	return acquireWithRetry(ctx, m.config, m.TryLock)
+}
+
+// TryLock 尝试获取锁，未在多数节点上获取成功时释放已获取的锁
+func (m *MultiLock) TryLock() bool {
+	if len(m.locks) == 0 {
+		return false
+	}
+
+	start := time.Now()
+	acquired, _ := m.each((*Lock).tryAcquire)
+
+	if validUntil, ok := m.validity(start, acquired); ok {
+		m.locked, m.validUntil = true, validUntil
+		return true
+	}
+
+	// 部分节点上可能已经写入，即使回复丢失也要释放
+	m.each((*Lock).release)
+	return false
+}
+
+// Unlock 释放所有节点上的锁
+func (m *MultiLock) Unlock() error {
+	if !m.locked {
+		return nil
+	}
+	m.locked, m.validUntil = false, time.Time{}
+
+	// 不可用节点上的锁会自动过期，只要有节点释放成功就不返回错误
+	released, err := m.each((*Lock).release)
+	if released > 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("unlock failed: lock not held on any node")
+}
+
+// LockFunc 使用闭包简化锁的使用
+func (m *MultiLock) LockFunc(fn func()) error {
+	if err := m.Lock(); err != nil {
+		return err
+	}
+	defer m.Unlock()
+
+	fn()
+	return nil
+}
+
+// TryLockFunc 尝试使用闭包简化锁的使用
+func (m *MultiLock) TryLockFunc(fn func()) bool {
+	if !m.TryLock() {
+		return false
+	}
+	defer m.Unlock()
+
+	fn()
+	return true
+}
+
+// IsLocked 检查是否已锁定且仍在有效期内
+func (m *MultiLock) IsLocked() bool {
+	return m.locked && time.Now().Before(m.validUntil)
+}
+
+// Validity 获取锁的剩余有效期，未持有时返回 0
+func (m *MultiLock) Validity() time.Duration {
+	if !m.IsLocked() {
+		return 0
+	}
+	return time.Until(m.validUntil)
+}
+
+// Refresh 在所有节点上刷新锁的过期时间，未在多数节点上刷新成功时视为锁已丢失
+func (m *MultiLock) Refresh() error {
+	if !m.locked {
+		return fmt.Errorf("lock not held")
+	}
+
+	start := time.Now()
+	renewed, _ := m.each((*Lock).renew)
+
+	if validUntil, ok := m.validity(start, renewed); ok {
+		m.validUntil = validUntil
+		return nil
+	}
+
+	m.locked, m.validUntil = false, time.Time{}
+	m.each((*Lock).release)
+	return fmt.Errorf("refresh failed: lock not held on a quorum of nodes")
+}
+
+// validity 计算扣除耗时和时钟漂移后的有效期截止时间，成功节点不足多数或已无有效期时返回 false
+func (m *MultiLock) validity(start time.Time, succeeded int) (time.Time, bool) {
+	if succeeded < len(m.locks)/2+1 {
+		return time.Time{}, false
+	}
+
+	drift := time.Duration(float64(m.config.WaitTime)*multiLockDriftFactor) + time.Millisecond*2
+	validity := m.config.WaitTime - time.Since(start) - drift
+	if validity <= 0 {
+		return time.Time{}, false
+	}
+	return time.Now().Add(validity), true
+}
+
+// each 在所有节点上并发执行 fn，返回 fn 成功且返回 true 的节点数量，以及所有节点的错误
+// 每个节点的操作最多执行 WaitTime 的一小部分时间，超时的节点视为失败
+func (m *MultiLock) each(fn func(l *Lock) (bool, error)) (int, error) {
+	timeout := time.Duration(float64(m.config.WaitTime) * multiLockNodeTimeoutFactor)
+
+	var wg sync.WaitGroup
+	results := make([]bool, len(m.locks))
+	errs := make([]error, len(m.locks))
+	for i, lock := range m.locks {
+		wg.Add(1)
+		go func(i int, lock *Lock) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(lock.redis.Context(), timeout)
+			defer cancel()
+			results[i], errs[i] = fn(lock.WithContext(ctx))
+		}(i, lock)
+	}
+	wg.Wait()
+
+	count := 0
+	for i, ok := range results {
+		if ok && errs[i] == nil {
+			count++
+		}
+	}
+	return count, errors.Join(errs...)
+}
@@ -0,0 +1,218 @@
+package redisTool
+
+import (
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestNodes 创建多个独立的 miniredis 节点
+func newTestNodes(t *testing.T, n int) ([]*Redis, []*miniredis.Miniredis) {
+	nodes := make([]*Redis, n)
+	servers := make([]*miniredis.Miniredis, n)
+	for i := range nodes {
+		servers[i] = miniredis.RunT(t)
+		nodes[i] = Builder(servers[i].Addr(), "").Config(Config{Prefix: "test:"}).Build()
+		node := nodes[i]
+		t.Cleanup(func() { node.Close() })
+	}
+	return nodes, servers
+}
+
+func TestMultiLock_LockUnlock(t *testing.T) {
+	nodes, servers := newTestNodes(t, 3)
+
+	lock1 := NewMultiLock("testlock", nodes, LockConfig{MaxGetLockWaitTime: 0})
+	lock2 := NewMultiLock("testlock", nodes, LockConfig{MaxGetLockWaitTime: 0})
+
+	if err := lock1.Lock(); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	if !lock1.IsLocked() {
+		t.Error("IsLocked() = false after Lock()")
+	}
+	if validity := lock1.Validity(); validity <= 0 || validity > time.Second*5 {
+		t.Errorf("Validity() = %v, want within (0, 5s]", validity)
+	}
+	for i, server := range servers {
+		if !server.Exists("test:lock:testlock") {
+			t.Errorf("node %d: lock key not set", i)
+		}
+	}
+
+	if lock2.TryLock() {
+		t.Fatal("TryLock() should fail while lock1 holds the lock")
+	}
+
+	if err := lock1.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	for i, server := range servers {
+		if server.Exists("test:lock:testlock") {
+			t.Errorf("node %d: lock key not released", i)
+		}
+	}
+
+	called := false
+	if err := lock2.LockFunc(func() { called = true }); err != nil || !called {
+		t.Errorf("LockFunc() = %v, called = %v", err, called)
+	}
+}
+
+func TestMultiLock_Quorum(t *testing.T) {
+	nodes, servers := newTestNodes(t, 3)
+
+	// 一个节点故障时仍能在多数节点上获取
+	servers[2].Close()
+	lock := NewMultiLock("testlock", nodes, LockConfig{MaxGetLockWaitTime: 0})
+	if !lock.TryLock() {
+		t.Fatal("TryLock() with 2 of 3 nodes available should succeed")
+	}
+	if err := lock.Refresh(); err != nil {
+		t.Errorf("Refresh() error = %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Errorf("Unlock() error = %v", err)
+	}
+
+	// 其他持有者占用一个节点后，只能在剩余一个节点上获取，未达到多数
+	other := nodes[0].NewLock("testlock")
+	if !other.TryLock() {
+		t.Fatal("TryLock() on a single node should succeed")
+	}
+	if lock.TryLock() {
+		t.Fatal("TryLock() without a quorum should fail")
+	}
+	if servers[1].Exists("test:lock:testlock") {
+		t.Error("partially acquired lock should be released")
+	}
+}
+
+func TestMultiLock_Refresh(t *testing.T) {
+	nodes, servers := newTestNodes(t, 3)
+
+	lock := NewMultiLock("testlock", nodes, LockConfig{WaitTime: time.Second * 2})
+	if err := lock.Refresh(); err == nil {
+		t.Error("Refresh() when not held should fail")
+	}
+
+	lock.Lock()
+	for _, server := range servers {
+		server.FastForward(time.Second)
+	}
+	if err := lock.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	for i, server := range servers {
+		if ttl := server.TTL("test:lock:testlock"); ttl != time.Second*2 {
+			t.Errorf("node %d: TTL after Refresh() = %v, want 2s", i, ttl)
+		}
+	}
+
+	// 多数节点上的锁过期后刷新失败
+	servers[0].Del("test:lock:testlock")
+	servers[1].Del("test:lock:testlock")
+	if err := lock.Refresh(); err == nil {
+		t.Error("Refresh() without a quorum should fail")
+	}
+	if lock.IsLocked() {
+		t.Error("IsLocked() = true after failed Refresh()")
+	}
+	if servers[2].Exists("test:lock:testlock") {
+		t.Error("lock should be released on remaining nodes after failed Refresh()")
+	}
+}
+
+// stallProxy 转发到 miniredis 的代理，stalled 为 true 时不再转发请求，模拟无响应的节点
+type stallProxy struct {
+	backend string
+	stalled atomic.Bool
+}
+
+// newStallProxy 启动代理并返回连接到代理的客户端
+func newStallProxy(t *testing.T) (*Redis, *stallProxy) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	proxy := &stallProxy{backend: miniredis.RunT(t).Addr()}
+	t.Cleanup(func() {
+		proxy.stalled.Store(false)
+		ln.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go proxy.serve(conn)
+		}
+	}()
+
+	node := Builder(ln.Addr().String(), "").Config(Config{Prefix: "test:"}).Build()
+	t.Cleanup(func() { node.Close() })
+	return node, proxy
+}
+
+// serve 转发一个客户端连接
+func (p *stallProxy) serve(conn net.Conn) {
+	defer conn.Close()
+	backend, err := net.Dial("tcp", p.backend)
+	if err != nil {
+		return
+	}
+	defer backend.Close()
+	go io.Copy(conn, backend)
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		for p.stalled.Load() {
+			time.Sleep(time.Millisecond * 10)
+		}
+		if _, err := backend.Write(buf[:n]); err != nil {
+			return
+		}
+	}
+}
+
+func TestMultiLock_StalledNode(t *testing.T) {
+	nodes, _ := newTestNodes(t, 2)
+	stalledNode, proxy := newStallProxy(t)
+	nodes = append(nodes, stalledNode)
+	proxy.stalled.Store(true)
+
+	lock := NewMultiLock("testlock", nodes, LockConfig{
+		WaitTime:           time.Second * 2,
+		MaxGetLockWaitTime: 0,
+	})
+
+	// 无响应的节点不能阻塞获取，剩余两个节点构成多数
+	start := time.Now()
+	if !lock.TryLock() {
+		t.Fatal("TryLock() with 2 of 3 nodes responsive should succeed")
+	}
+	if elapsed := time.Since(start); elapsed > time.Millisecond*500 {
+		t.Errorf("TryLock() took %v, want it bounded by the node timeout", elapsed)
+	}
+	if validity := lock.Validity(); validity < time.Second {
+		t.Errorf("Validity() = %v, want most of WaitTime left", validity)
+	}
+
+	start = time.Now()
+	if err := lock.Unlock(); err != nil {
+		t.Errorf("Unlock() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Millisecond*500 {
+		t.Errorf("Unlock() took %v, want it bounded by the node timeout", elapsed)
+	}
+}
@@ -0,0 +1,149 @@
+package redisTool
+
+import (
+	"errors"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ErrNotExecuted 命令尚未执行
+var ErrNotExecuted = errors.New("redisTool: command not executed")
+
+// Sender 命令发送器，Pipeline 和 Tx 都实现了该接口
+// 各数据结构的 SendXxx 方法通过它把命令排入管道或事务
+type Sender interface {
+	Send(commandName string, args ...interface{}) *Result
+}
+
+// Pipeline 管道，在同一个连接上批量发送命令，一次刷新、一次往返
+type Pipeline struct {
+	redis    *Redis
+	commands []pipelineCommand
+	results  []*Result
+}
+
+// pipelineCommand 排队中的命令
+type pipelineCommand struct {
+	name string
+	args []interface{}
+}
+
+// Result 命令执行结果，在 Exec 之后可用
+type Result struct {
+	reply interface{}
+	err   error
+}
+
+// Pipeline 创建管道
+func (r *Redis) Pipeline() *Pipeline {
+	return &Pipeline{
+		redis: r,
+	}
+}
+
+// Send 将命令加入管道，返回的结果在 Exec 之后可用
+func (p *Pipeline) Send(commandName string, args ...interface{}) *Result {
+	result := &Result{err: ErrNotExecuted}
+	p.commands = append(p.commands, pipelineCommand{name: commandName, args: args})
+	p.results = append(p.results, result)
+	return result
+}
+
+// Len 获取排队中的命令数量
+func (p *Pipeline) Len() int {
+	return len(p.commands)
+}
+
+// Exec 发送所有排队的命令并读取结果，返回第一个出错命令的错误
+// 执行后管道被清空，可以继续复用
+func (p *Pipeline) Exec() error {
+	commands, results := p.commands, p.results
+	p.commands, p.results = nil, nil
+
+	if len(commands) == 0 {
+		return nil
+	}
+
+	conn := p.redis.GetConn()
+	defer conn.Close()
+
+	for _, cmd := range commands {
+		if err := conn.Send(cmd.name, cmd.args...); err != nil {
+			return failResults(results, err)
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		return failResults(results, err)
+	}
+
+	var firstErr error
+	for i, result := range results {
+		reply, err := conn.Receive()
+		result.reply, result.err = reply, err
+		if err == nil {
+			continue
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		// 命令错误不影响后续结果，连接错误则后续结果都无法读取
+		if _, ok := err.(redis.Error); !ok {
+			failResults(results[i+1:], err)
+			break
+		}
+	}
+	return firstErr
+}
+
+// failResults 将所有结果标记为失败
+func failResults(results []*Result, err error) error {
+	for _, result := range results {
+		result.reply, result.err = nil, err
+	}
+	return err
+}
+
+// Value 获取原始回复
+func (r *Result) Value() (interface{}, error) {
+	return r.reply, r.err
+}
+
+// Err 获取错误
+func (r *Result) Err() error {
+	return r.err
+}
+
+// Int 获取整数结果
+func (r *Result) Int() (int, error) {
+	return redis.Int(r.reply, r.err)
+}
+
+// Int64 获取 64 位整数结果
+func (r *Result) Int64() (int64, error) {
+	return redis.Int64(r.reply, r.err)
+}
+
+// Float64 获取浮点数结果
+func (r *Result) Float64() (float64, error) {
+	return redis.Float64(r.reply, r.err)
+}
+
+// String 获取字符串结果
+func (r *Result) String() (string, error) {
+	return redis.String(r.reply, r.err)
+}
+
+// Bytes 获取字节切片结果
+func (r *Result) Bytes() ([]byte, error) {
+	return redis.Bytes(r.reply, r.err)
+}
+
+// Bool 获取布尔结果
+func (r *Result) Bool() (bool, error) {
+	return redis.Bool(r.reply, r.err)
+}
+
+// Strings 获取字符串数组结果
+func (r *Result) Strings() ([]string, error) {
+	return redis.Strings(r.reply, r.err)
+}
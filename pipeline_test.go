@@ -0,0 +1,67 @@
+package redisTool
+
+import (
+	"testing"
+)
+
+func TestPipeline_Exec(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	pipe := tr.Redis.Pipeline()
+	setResult := pipe.Send("SET", "pipekey", "value")
+	incrResult := pipe.Send("INCR", "pipecounter")
+	getResult := pipe.Send("GET", "pipekey")
+
+	if pipe.Len() != 3 {
+		t.Errorf("Len() = %v, want 3", pipe.Len())
+	}
+
+	// Exec 之前结果不可用
+	if _, err := getResult.String(); err != ErrNotExecuted {
+		t.Errorf("String() before Exec error = %v, want ErrNotExecuted", err)
+	}
+
+	if err := pipe.Exec(); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	if s, err := setResult.String(); err != nil || s != "OK" {
+		t.Errorf("SET result = %v, %v, want OK", s, err)
+	}
+	if n, err := incrResult.Int(); err != nil || n != 1 {
+		t.Errorf("INCR result = %v, %v, want 1", n, err)
+	}
+	if s, err := getResult.String(); err != nil || s != "value" {
+		t.Errorf("GET result = %v, %v, want value", s, err)
+	}
+
+	// 执行后管道被清空
+	if pipe.Len() != 0 {
+		t.Errorf("Len() after Exec = %v, want 0", pipe.Len())
+	}
+	if err := pipe.Exec(); err != nil {
+		t.Errorf("Exec() on empty pipeline error = %v", err)
+	}
+}
+
+func TestPipeline_CommandError(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	tr.Redis.Do("SET", "pipekey", "notanumber")
+
+	pipe := tr.Redis.Pipeline()
+	bad := pipe.Send("INCR", "pipekey")
+	good := pipe.Send("GET", "pipekey")
+
+	if err := pipe.Exec(); err == nil {
+		t.Error("Exec() should return the command error")
+	}
+	if bad.Err() == nil {
+		t.Error("INCR on string should fail")
+	}
+	if s, err := good.String(); err != nil || s != "notanumber" {
+		t.Errorf("GET after failed command = %v, %v, want notanumber", s, err)
+	}
+}
@@ -1,13 +1,18 @@
 package redisTool
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/gomodule/redigo/redis"
 )
 
+// queuePollInterval 原子获取任务时的轮询间隔
+const queuePollInterval = time.Millisecond * 100
+
 // Queue 队列
 type Queue[T any] struct {
 	redis         *Redis
@@ -15,21 +20,38 @@ type Queue[T any] struct {
 	delayedName   string
 	processingName string
 	retryName     string
+	deadName      string
+	priorityName  string
+	taskPriorities string // 记录非 0 优先级任务的优先级，重试和超时放回时回到原来的优先级
 	config        QueueConfig
 }
 
+// queuePushScript Lua 脚本中按任务原来的优先级放回队列的函数
+// KEYS[1] 为主队列，KEYS[2] 为非空优先级集合，KEYS[3] 为任务优先级哈希，ARGV[1] 为优先级列表前缀
+const queuePushScript = `
+	local function pushTask(item)
+		local level = redis.call('HGET', KEYS[3], item)
+		if level then
+			redis.call('ZADD', KEYS[2], level, level)
+			redis.call('RPUSH', ARGV[1] .. level, item)
+		else
+			redis.call('RPUSH', KEYS[1], item)
+		end
+	end
+`
+
+// WithContext 返回绑定了上下文的队列视图，阻塞的 Take 也会随上下文取消而返回
+func (q *Queue[T]) WithContext(ctx context.Context) *Queue[T] {
+	qc := *q
+	qc.redis = q.redis.WithContext(ctx)
+	return &qc
+}
 
 // Add 添加任务到队列
 func (q *Queue[T]) Add(value T) error {
 	// 检查队列长度
-	if q.config.MaxLength > 0 {
-		length, err := redis.Int(q.redis.Do("LLEN", q.name))
-		if err != nil {
-			return err
-		}
-		if length >= q.config.MaxLength {
-			return fmt.Errorf("queue is full, max length: %d", q.config.MaxLength)
-		}
+	if err := q.checkLength(); err != nil {
+		return err
 	}
 	
 	data, err := q.redis.Serialize(value)
@@ -41,6 +63,39 @@ func (q *Queue[T]) Add(value T) error {
 	return err
 }
 
+// AddWithPriority 按优先级添加任务
+// Take 总是先取优先级高的任务，同一优先级内先进先出；Add 添加的任务优先级为 0，
+// 负数优先级的任务排在普通任务之后；重试、超时和从死信队列放回的任务回到原来的优先级。
+// 任务的优先级在 Complete 或 Fail 后删除，内容相同的任务共享同一个优先级记录；
+// 处理状态同样以任务内容为键，需要区分内容相同的任务时在任务中加入唯一 ID
+func (q *Queue[T]) AddWithPriority(value T, priority int) error {
+	if priority == 0 {
+		return q.Add(value)
+	}
+	
+	if err := q.checkLength(); err != nil {
+		return err
+	}
+	
+	data, err := q.redis.Serialize(value)
+	if err != nil {
+		return err
+	}
+	
+	script := `
+		redis.call('ZADD', KEYS[2], ARGV[1], ARGV[1])
+		redis.call('HSET', KEYS[3], ARGV[2], ARGV[1])
+		return redis.call('RPUSH', KEYS[1], ARGV[2])
+	`
+	
+	conn := q.redis.GetConn()
+	defer conn.Close()
+	
+	luaScript := redis.NewScript(3, script)
+	_, err = luaScript.Do(conn, q.priorityListName(priority), q.priorityName, q.taskPriorities, priority, data)
+	return err
+}
+
 // AddDelayed 添加延迟任务
 func (q *Queue[T]) AddDelayed(value T, delay time.Duration) error {
 	data, err := q.redis.Serialize(value)
@@ -54,25 +109,37 @@ func (q *Queue[T]) AddDelayed(value T, delay time.Duration) error {
 }
 
 // Take 获取任务
+// 需要跟踪处理中任务时（MaxRetry 或 VisibilityTimeout 大于 0），弹出任务与放入处理中队列在同一个 Lua 脚本中原子完成；
+// 处理中队列、失败次数和优先级都以序列化后的任务内容为键，内容相同的任务同时处理时只有一条处理中记录，
+// 其中一个 Complete 后另一个不再被超时回收，失败次数也会累加到一起，需要区分时在任务中加入唯一 ID
 func (q *Queue[T]) Take() (T, bool) {
 	var zero T
 	
-	// 首先处理延迟任务
+	// 首先处理延迟任务和处理超时的任务
 	q.processDelayedTasks()
+	if q.config.VisibilityTimeout > 0 {
+		q.RequeueExpired()
+	}
 	
 	var data []byte
 	var err error
 	
-	if q.config.MaxWaitTime > 0 {
-		// 阻塞获取
+	if !q.tracking() && q.config.MaxWaitTime > 0 && !q.hasPriorities() {
+		// 阻塞获取（存在优先级任务时 BLPOP 无法保证顺序，改为轮询）
 		values, err := redis.ByteSlices(q.redis.Do("BLPOP", q.name, int(q.config.MaxWaitTime.Seconds())))
 		if err != nil || len(values) < 2 {
 			return zero, false
 		}
 		data = values[1]
+	} else if q.config.MaxWaitTime > 0 {
+		// 原子弹出无法阻塞，轮询直到超时
+		data, err = q.pollTake(q.config.MaxWaitTime)
+		if err != nil || len(data) == 0 {
+			return zero, false
+		}
 	} else {
 		// 非阻塞获取
-		data, err = redis.Bytes(q.redis.Do("LPOP", q.name))
+		data, err = q.takeData()
 		if err != nil || len(data) == 0 {
 			return zero, false
 		}
@@ -85,35 +152,119 @@ func (q *Queue[T]) Take() (T, bool) {
 	}
 	value = reflect.ValueOf(result).Elem().Interface().(T)
 	
-	// 将任务移到处理中队列
-	if q.config.MaxRetry > 0 {
-		q.redis.Do("ZADD", q.processingName, float64(time.Now().UnixMilli()), data)
+	return value, true
+}
+
+// takeData 按优先级从高到低原子性地弹出任务，需要跟踪时同时放入处理中队列
+// 优先级 0 的任务存放在主队列中，其他优先级各自一个列表，KEYS[3] 记录非空的优先级；
+// 处理中队列的分数为 Redis 服务器时间，各工作进程的时钟偏差不影响超时判断
+func (q *Queue[T]) takeData() ([]byte, error) {
+	script := lockNowScript + `
+		local item
+		local mainTried = false
+		local levels = redis.call('ZREVRANGE', KEYS[3], 0, -1, 'WITHSCORES')
+		for i = 1, #levels, 2 do
+			if tonumber(levels[i + 1]) < 0 and not mainTried then
+				mainTried = true
+				item = redis.call('LPOP', KEYS[1])
+				if item then
+					break
+				end
+			end
+			local key = ARGV[2] .. levels[i]
+			item = redis.call('LPOP', key)
+			if redis.call('LLEN', key) == 0 then
+				redis.call('ZREM', KEYS[3], levels[i])
+			end
+			if item then
+				break
+			end
+		end
+		if not item and not mainTried then
+			item = redis.call('LPOP', KEYS[1])
+		end
+		if item and ARGV[1] == '1' then
+			redis.call('ZADD', KEYS[2], now, item)
+		end
+		return item
+	`
+	
+	track := 0
+	if q.tracking() {
+		track = 1
 	}
 	
-	return value, true
+	conn := q.redis.GetConn()
+	defer conn.Close()
+	
+	luaScript := redis.NewScript(3, script)
+	return redis.Bytes(luaScript.Do(conn, q.name, q.processingName, q.priorityName, track, q.priorityPrefix()))
+}
+
+// pollTake 轮询获取任务，直到超时或上下文被取消
+func (q *Queue[T]) pollTake(wait time.Duration) ([]byte, error) {
+	ctx := q.redis.Context()
+	deadline := time.Now().Add(wait)
+	
+	for {
+		data, err := q.takeData()
+		if err != redis.ErrNil {
+			return data, err
+		}
+		
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, err
+		}
+		if remaining > queuePollInterval {
+			remaining = queuePollInterval
+		}
+		
+		timer := time.NewTimer(remaining)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
 }
 
 // Complete 完成任务
 func (q *Queue[T]) Complete(value T) error {
-	if q.config.MaxRetry == 0 {
-		return nil
-	}
-	
 	data, err := q.redis.Serialize(value)
 	if err != nil {
 		return err
 	}
+	return q.complete(data)
+}
+
+// complete 按序列化后的数据完成任务
+func (q *Queue[T]) complete(data []byte) error {
+	if !q.tracking() {
+		_, err := q.redis.Do("HDEL", q.taskPriorities, data)
+		return err
+	}
 	
-	_, err = q.redis.Do("ZREM", q.processingName, data)
-	return err
+	pipe := q.redis.Pipeline()
+	pipe.Send("ZREM", q.processingName, data)
+	pipe.Send("HDEL", q.retryName, data)
+	pipe.Send("HDEL", q.taskPriorities, data)
+	return pipe.Exec()
 }
 
 // Fail 任务失败
+// 每次失败都会累加任务的失败次数，失败次数超过 MaxRetry 的任务进入死信队列；MaxRetry 为 0 时第一次失败就进入死信队列
 func (q *Queue[T]) Fail(value T, err error) error {
 	if q.config.ErrorHandler == nil {
 		return q.Complete(value)
 	}
 	
+	data, serr := q.redis.Serialize(value)
+	if serr != nil {
+		return serr
+	}
+	
 	// 调用错误处理器
 	retryDelay := q.config.ErrorHandler(value, err, func(v interface{}) {
 		if updatedValue, ok := v.(T); ok {
@@ -122,28 +273,167 @@ func (q *Queue[T]) Fail(value T, err error) error {
 	})
 	
 	if retryDelay < 0 {
-		// 不重试，直接完成
-		return q.Complete(value)
+		// 不重试，直接完成（错误处理器可能修改了任务，按原始数据移除）
+		return q.complete(data)
 	}
 	
-	// 重新加入延迟队列
-	if retryDelay > 0 {
-		return q.AddDelayed(value, retryDelay)
+	// MaxRetry 为 0 表示不重试，第一次失败就进入死信队列
+	if q.config.MaxRetry <= 0 {
+		return q.bury(data)
+	}
+	attempts, err := redis.Int(q.redis.Do("HINCRBY", q.retryName, data, 1))
+	if err != nil {
+		return err
+	}
+	if attempts > q.config.MaxRetry {
+		return q.bury(data)
 	}
 	
-	// 立即重试
-	return q.Add(value)
+	newData, err := q.redis.Serialize(value)
+	if err != nil {
+		return err
+	}
+	return q.retry(data, newData, retryDelay)
 }
 
-// Length 获取队列长度
+// retry 在同一个 Lua 脚本中把任务从处理中队列移除并按原来的优先级重新放回，重试的任务不受 MaxLength 限制
+// 错误处理器修改了任务时，失败次数和优先级跟随新的任务内容；delay 大于 0 时放入延迟队列
+func (q *Queue[T]) retry(data, newData []byte, delay time.Duration) error {
+	script := queuePushScript + `
+		local function move(key, from, to)
+			local value = redis.call('HGET', key, from)
+			if value then
+				redis.call('HDEL', key, from)
+				redis.call('HSET', key, to, value)
+			end
+		end
+
+		redis.call('ZREM', KEYS[4], ARGV[2])
+		if ARGV[2] ~= ARGV[3] then
+			move(KEYS[5], ARGV[2], ARGV[3])
+			move(KEYS[3], ARGV[2], ARGV[3])
+		end
+		if tonumber(ARGV[4]) > 0 then
+			redis.call('ZADD', KEYS[6], ARGV[4], ARGV[3])
+		else
+			pushTask(ARGV[3])
+		end
+		return 1
+	`
+	
+	var score int64
+	if delay > 0 {
+		score = time.Now().Add(delay).UnixMilli()
+	}
+	
+	conn := q.redis.GetConn()
+	defer conn.Close()
+	
+	luaScript := redis.NewScript(6, script)
+	_, err := luaScript.Do(conn, q.name, q.priorityName, q.taskPriorities, q.processingName, q.retryName, q.delayedName,
+		q.priorityPrefix(), data, newData, score)
+	return err
+}
+
+// DeadLetters 获取死信队列中的所有任务
+func (q *Queue[T]) DeadLetters() ([]T, error) {
+	data, err := redis.ByteSlices(q.redis.Do("LRANGE", q.deadName, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	
+	var zero T
+	result := make([]T, 0, len(data))
+	for _, d := range data {
+		item := reflect.New(reflect.TypeOf(zero)).Interface()
+		if err := q.redis.Deserialize(d, item); err != nil {
+			continue
+		}
+		result = append(result, reflect.ValueOf(item).Elem().Interface().(T))
+	}
+	return result, nil
+}
+
+// DeadLength 获取死信队列长度
+func (q *Queue[T]) DeadLength() int {
+	length, err := redis.Int(q.redis.Do("LLEN", q.deadName))
+	if err != nil {
+		return 0
+	}
+	return length
+}
+
+// RequeueDead 将死信队列中最早的 n 个任务按原来的优先级放回队列并重置失败次数，n <= 0 表示全部，返回放回的数量
+func (q *Queue[T]) RequeueDead(n int) (int, error) {
+	script := queuePushScript + `
+		local count = tonumber(ARGV[2])
+		if count <= 0 then
+			count = redis.call('LLEN', KEYS[4])
+		end
+		local requeued = 0
+		for i = 1, count do
+			local item = redis.call('LPOP', KEYS[4])
+			if not item then
+				break
+			end
+			redis.call('HDEL', KEYS[5], item)
+			pushTask(item)
+			requeued = requeued + 1
+		end
+		return requeued
+	`
+	
+	conn := q.redis.GetConn()
+	defer conn.Close()
+	
+	luaScript := redis.NewScript(5, script)
+	return redis.Int(luaScript.Do(conn, q.name, q.priorityName, q.taskPriorities, q.deadName, q.retryName,
+		q.priorityPrefix(), n))
+}
+
+// PurgeDead 清空死信队列
+func (q *Queue[T]) PurgeDead() error {
+	script := `
+		for i, item in ipairs(redis.call('LRANGE', KEYS[1], 0, -1)) do
+			redis.call('HDEL', KEYS[2], item)
+		end
+		return redis.call('DEL', KEYS[1])
+	`
+	
+	conn := q.redis.GetConn()
+	defer conn.Close()
+	
+	luaScript := redis.NewScript(2, script)
+	_, err := luaScript.Do(conn, q.deadName, q.taskPriorities)
+	return err
+}
+
+// Length 获取队列长度（包括所有优先级的任务）
 func (q *Queue[T]) Length() int {
-	length, err := redis.Int(q.redis.Do("LLEN", q.name))
+	length, err := q.length()
 	if err != nil {
 		return 0
 	}
 	return length
 }
 
+// length 统计主队列和各优先级列表的任务总数
+func (q *Queue[T]) length() (int, error) {
+	script := `
+		local total = redis.call('LLEN', KEYS[1])
+		for i, level in ipairs(redis.call('ZRANGE', KEYS[2], 0, -1)) do
+			total = total + redis.call('LLEN', ARGV[1] .. level)
+		end
+		return total
+	`
+	
+	conn := q.redis.GetConn()
+	defer conn.Close()
+	
+	luaScript := redis.NewScript(2, script)
+	return redis.Int(luaScript.Do(conn, q.name, q.priorityName, q.priorityPrefix()))
+}
+
 // DelayedLength 获取延迟队列长度
 func (q *Queue[T]) DelayedLength() int {
 	length, err := redis.Int(q.redis.Do("ZCARD", q.delayedName))
@@ -164,56 +454,172 @@ func (q *Queue[T]) ProcessingLength() int {
 
 // Clear 清空队列
 func (q *Queue[T]) Clear() error {
+	script := `
+		for i, level in ipairs(redis.call('ZRANGE', KEYS[1], 0, -1)) do
+			redis.call('DEL', ARGV[1] .. level)
+		end
+		redis.call('DEL', KEYS[1], KEYS[2], KEYS[3], KEYS[4], KEYS[5], KEYS[6], KEYS[7])
+		return 1
+	`
+	
 	conn := q.redis.GetConn()
 	defer conn.Close()
 	
-	_, err := conn.Do("DEL", q.name, q.delayedName, q.processingName, q.retryName)
+	luaScript := redis.NewScript(7, script)
+	_, err := luaScript.Do(conn, q.priorityName, q.name, q.delayedName, q.processingName, q.retryName, q.deadName,
+		q.taskPriorities, q.priorityPrefix())
 	return err
 }
 
-// processDelayedTasks 处理延迟任务
-func (q *Queue[T]) processDelayedTasks() {
-	now := float64(time.Now().UnixMilli())
+// RequeueExpired 将处理超过 VisibilityTimeout 仍未完成的任务放回队列，返回放回的数量
+// 超时与 Fail 共用失败次数，超过 MaxRetry 的任务进入死信队列；超时按 Redis 服务器时间判断
+func (q *Queue[T]) RequeueExpired() (int, error) {
+	if q.config.VisibilityTimeout <= 0 {
+		return 0, nil
+	}
 	
-	// 使用 Lua 脚本原子性地移动任务
-	script := `
-		local items = redis.call('ZRANGEBYSCORE', KEYS[1], 0, ARGV[1])
+	script := lockNowScript + queuePushScript + `
+		local items = redis.call('ZRANGEBYSCORE', KEYS[4], 0, now - tonumber(ARGV[2]))
+		local requeued = 0
 		for i, item in ipairs(items) do
-			redis.call('ZREM', KEYS[1], item)
-			redis.call('RPUSH', KEYS[2], item)
+			redis.call('ZREM', KEYS[4], item)
+			local attempts = redis.call('HINCRBY', KEYS[5], item, 1)
+			if attempts > tonumber(ARGV[3]) then
+				redis.call('HDEL', KEYS[5], item)
+				redis.call('RPUSH', KEYS[6], item)
+			else
+				pushTask(item)
+				requeued = requeued + 1
+			end
 		end
-		return #items
+		return requeued
 	`
 	
 	conn := q.redis.GetConn()
 	defer conn.Close()
 	
-	luaScript := redis.NewScript(2, script)
-	luaScript.Do(conn, q.delayedName, q.name, now)
+	luaScript := redis.NewScript(6, script)
+	return redis.Int(luaScript.Do(conn, q.name, q.priorityName, q.taskPriorities, q.processingName, q.retryName,
+		q.deadName, q.priorityPrefix(), q.config.VisibilityTimeout.Milliseconds(), q.config.MaxRetry))
 }
 
-// StartWorker 启动工作线程
-func (q *Queue[T]) StartWorker(handler func(value T) error) {
+// StartReaper 启动定期放回超时任务的循环，关闭返回的通道即可停止
+func (q *Queue[T]) StartReaper(interval time.Duration) chan struct{} {
+	stopCh := make(chan struct{})
+	
 	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		
 		for {
-			value, ok := q.Take()
-			if !ok {
-				time.Sleep(time.Second)
-				continue
-			}
-			
-			if err := handler(value); err != nil {
-				q.Fail(value, err)
-			} else {
-				q.Complete(value)
+			select {
+			case <-ticker.C:
+				q.RequeueExpired()
+			case <-stopCh:
+				return
 			}
 		}
 	}()
+	
+	return stopCh
 }
 
-// StartWorkers 启动多个工作线程
-func (q *Queue[T]) StartWorkers(count int, handler func(value T) error) {
-	for i := 0; i < count; i++ {
-		q.StartWorker(handler)
+// bury 将任务移入死信队列
+func (q *Queue[T]) bury(data []byte) error {
+	script := `
+		redis.call('ZREM', KEYS[1], ARGV[1])
+		redis.call('HDEL', KEYS[2], ARGV[1])
+		redis.call('RPUSH', KEYS[3], ARGV[1])
+		return 1
+	`
+	
+	conn := q.redis.GetConn()
+	defer conn.Close()
+	
+	luaScript := redis.NewScript(3, script)
+	_, err := luaScript.Do(conn, q.processingName, q.retryName, q.deadName, data)
+	return err
+}
+
+// checkLength 检查队列是否已满
+func (q *Queue[T]) checkLength() error {
+	if q.config.MaxLength <= 0 {
+		return nil
+	}
+	
+	length, err := q.length()
+	if err != nil {
+		return err
+	}
+	if length >= q.config.MaxLength {
+		return fmt.Errorf("queue is full, max length: %d", q.config.MaxLength)
+	}
+	return nil
+}
+
+// hasPriorities 判断是否存在非 0 优先级的任务
+func (q *Queue[T]) hasPriorities() bool {
+	count, err := redis.Int(q.redis.Do("ZCARD", q.priorityName))
+	return err != nil || count > 0
+}
+
+// priorityPrefix 优先级列表键名前缀
+func (q *Queue[T]) priorityPrefix() string {
+	return q.priorityName + ":"
+}
+
+// priorityListName 获取指定优先级的列表键名
+func (q *Queue[T]) priorityListName(priority int) string {
+	return q.priorityPrefix() + strconv.Itoa(priority)
+}
+
+// tracking 是否需要跟踪处理中的任务
+func (q *Queue[T]) tracking() bool {
+	return q.config.MaxRetry > 0 || q.config.VisibilityTimeout > 0
+}
+
+// processDelayedTasks 处理延迟任务
+func (q *Queue[T]) processDelayedTasks() {
+	now := float64(time.Now().UnixMilli())
+	
+	// 使用 Lua 脚本原子性地移动任务，重试的任务回到原来的优先级
+	script := queuePushScript + `
+		local items = redis.call('ZRANGEBYSCORE', KEYS[4], 0, ARGV[2])
+		for i, item in ipairs(items) do
+			redis.call('ZREM', KEYS[4], item)
+			pushTask(item)
+		end
+		return #items
+	`
+	
+	conn := q.redis.GetConn()
+	defer conn.Close()
+	
+	luaScript := redis.NewScript(4, script)
+	luaScript.Do(conn, q.name, q.priorityName, q.taskPriorities, q.delayedName, q.priorityPrefix(), now)
+}
+
+// StartWorker 启动工作线程，返回的线程池可用于调整并发数、查看统计信息和停止
+func (q *Queue[T]) StartWorker(handler func(value T) error) *WorkerPool[T] {
+	return q.StartWorkers(1, handler)
+}
+
+// StartWorkers 启动多个工作线程，返回的线程池可用于调整并发数、查看统计信息和停止
+func (q *Queue[T]) StartWorkers(count int, handler func(value T) error) *WorkerPool[T] {
+	return newWorkerPool(q.takeTask, q.config.IdleBackoff, q.config.MaxIdleBackoff, count, handler)
+}
+
+// takeTask 取出任务供工作线程池处理，处理成功时 Complete，失败时 Fail
+func (q *Queue[T]) takeTask() (T, func(err error), bool) {
+	value, ok := q.Take()
+	if !ok {
+		return value, nil, false
 	}
+	return value, func(err error) {
+		if err != nil {
+			q.Fail(value, err)
+		} else {
+			q.Complete(value)
+		}
+	}, true
 }
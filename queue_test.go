@@ -4,6 +4,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/gomodule/redigo/redis"
 )
 
 func TestQueue_AddTake(t *testing.T) {
@@ -253,6 +255,41 @@ func TestQueue_FailImmediateRetry(t *testing.T) {
 	}
 }
 
+func TestQueue_FailRetryIgnoresMaxLength(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxLength: 1,
+		MaxRetry:  3,
+		ErrorHandler: func(value interface{}, err error, storage func(value interface{})) time.Duration {
+			return 0
+		},
+	}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+	value, ok := queue.Take()
+	if !ok {
+		t.Fatal("Take() returned false")
+	}
+
+	// 队列在任务处理期间被填满
+	if err := queue.Add(TestStruct{Name: "Bob", Age: 25}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// 重试的任务不受 MaxLength 限制，不能丢失
+	if err := queue.Fail(value, errors.New("test error")); err != nil {
+		t.Fatalf("Fail() error = %v", err)
+	}
+	if length := queue.Length(); length != 2 {
+		t.Errorf("Length() after Fail() = %v, want 2", length)
+	}
+	if processing := queue.ProcessingLength(); processing != 0 {
+		t.Errorf("ProcessingLength() after Fail() = %v, want 0", processing)
+	}
+}
+
 func TestQueue_MaxLength(t *testing.T) {
 	tr := NewTestRedis(t)
 	defer tr.Close()
@@ -405,3 +442,504 @@ func TestQueue_ErrorHandlerStorage(t *testing.T) {
 		t.Error("Storage function should have been called")
 	}
 }
+
+func TestQueue_TakeMovesToProcessing(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxRetry: 3,
+	}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+
+	value, ok := queue.Take()
+	if !ok {
+		t.Fatal("Take() returned false")
+	}
+
+	if queue.Length() != 0 || queue.ProcessingLength() != 1 {
+		t.Errorf("Length() = %v, ProcessingLength() = %v, want 0 and 1", queue.Length(), queue.ProcessingLength())
+	}
+
+	queue.Complete(value)
+	if queue.ProcessingLength() != 0 {
+		t.Errorf("ProcessingLength() after Complete = %v, want 0", queue.ProcessingLength())
+	}
+}
+
+func TestQueue_BlockingTakeWithTracking(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxWaitTime: time.Second * 2,
+		MaxRetry:    3,
+	}, tr.Redis)
+
+	go func() {
+		time.Sleep(time.Millisecond * 200)
+		queue.Add(TestStruct{Name: "Alice", Age: 30})
+	}()
+
+	value, ok := queue.Take()
+	if !ok || value.Name != "Alice" {
+		t.Fatalf("Take() = %v, %v, want Alice", value, ok)
+	}
+	if queue.ProcessingLength() != 1 {
+		t.Errorf("ProcessingLength() = %v, want 1", queue.ProcessingLength())
+	}
+}
+
+func TestQueue_VisibilityTimeout(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxRetry:          3,
+		VisibilityTimeout: time.Millisecond * 50,
+	}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+
+	// 取出后不完成，模拟工作进程崩溃
+	if _, ok := queue.Take(); !ok {
+		t.Fatal("Take() returned false")
+	}
+
+	requeued, err := queue.RequeueExpired()
+	if err != nil || requeued != 0 {
+		t.Errorf("RequeueExpired() before timeout = %v, %v, want 0", requeued, err)
+	}
+
+	time.Sleep(time.Millisecond * 100)
+
+	requeued, err = queue.RequeueExpired()
+	if err != nil || requeued != 1 {
+		t.Fatalf("RequeueExpired() = %v, %v, want 1", requeued, err)
+	}
+	if queue.Length() != 1 || queue.ProcessingLength() != 0 {
+		t.Errorf("Length() = %v, ProcessingLength() = %v, want 1 and 0", queue.Length(), queue.ProcessingLength())
+	}
+
+	value, ok := queue.Take()
+	if !ok || value.Name != "Alice" {
+		t.Errorf("Take() after requeue = %v, %v, want Alice", value, ok)
+	}
+}
+
+func TestQueue_VisibilityTimeoutServerTime(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+	if tr.MiniRedis == nil {
+		t.Skip("server clock test requires miniredis")
+	}
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxRetry:          3,
+		VisibilityTimeout: time.Minute,
+	}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+
+	// 服务器时间与客户端相差一小时，处理中分数和超时判断都按服务器时间计算
+	serverNow := time.Now().Add(-time.Hour)
+	tr.MiniRedis.SetTime(serverNow)
+	if _, ok := queue.Take(); !ok {
+		t.Fatal("Take() returned false")
+	}
+
+	if requeued, err := queue.RequeueExpired(); err != nil || requeued != 0 {
+		t.Errorf("RequeueExpired() before timeout = %v, %v, want 0", requeued, err)
+	}
+
+	tr.MiniRedis.SetTime(serverNow.Add(time.Minute * 2))
+	if requeued, err := queue.RequeueExpired(); err != nil || requeued != 1 {
+		t.Errorf("RequeueExpired() after server timeout = %v, %v, want 1", requeued, err)
+	}
+}
+
+func TestQueue_VisibilityTimeoutMaxRetry(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxRetry:          1,
+		VisibilityTimeout: time.Millisecond * 20,
+	}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+
+	// 第一次超时放回队列
+	queue.Take()
+	time.Sleep(time.Millisecond * 40)
+	if requeued, _ := queue.RequeueExpired(); requeued != 1 {
+		t.Fatalf("first RequeueExpired() = %v, want 1", requeued)
+	}
+
+	// 超过 MaxRetry 后不再放回，进入死信队列
+	if _, ok := queue.Take(); !ok {
+		t.Fatal("Take() after requeue returned false")
+	}
+	time.Sleep(time.Millisecond * 40)
+	if requeued, _ := queue.RequeueExpired(); requeued != 0 {
+		t.Errorf("second RequeueExpired() = %v, want 0", requeued)
+	}
+	if queue.Length() != 0 || queue.ProcessingLength() != 0 {
+		t.Errorf("Length() = %v, ProcessingLength() = %v, want 0 and 0", queue.Length(), queue.ProcessingLength())
+	}
+	if queue.DeadLength() != 1 {
+		t.Errorf("DeadLength() = %v, want 1", queue.DeadLength())
+	}
+}
+
+func TestQueue_FailMaxRetryZero(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		ErrorHandler: func(value interface{}, err error, storage func(value interface{})) time.Duration {
+			return 0
+		},
+	}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+	value, _ := queue.Take()
+	queue.Fail(value, errors.New("failed"))
+
+	// MaxRetry 为 0 时不重试，第一次失败就进入死信队列
+	if _, ok := queue.Take(); ok {
+		t.Error("Take() after Fail should return false")
+	}
+	if queue.DeadLength() != 1 {
+		t.Errorf("DeadLength() = %v, want 1", queue.DeadLength())
+	}
+}
+
+func TestQueue_StartReaper(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxRetry:          3,
+		VisibilityTimeout: time.Millisecond * 20,
+	}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+	queue.Take()
+
+	stopCh := queue.StartReaper(time.Millisecond * 10)
+	defer close(stopCh)
+
+	timeout := time.After(time.Second)
+	for queue.Length() != 1 {
+		select {
+		case <-timeout:
+			t.Fatal("reaper did not requeue the expired task")
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+}
+
+func TestQueue_FailDeadLetter(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxRetry: 2,
+		ErrorHandler: func(value interface{}, err error, storage func(value interface{})) time.Duration {
+			return 0 // Immediate retry
+		},
+	}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+
+	// 前两次失败重新入队，第三次失败进入死信队列
+	for i := 0; i < 3; i++ {
+		value, ok := queue.Take()
+		if !ok {
+			t.Fatalf("Take() #%d returned false", i+1)
+		}
+		if err := queue.Fail(value, errors.New("test error")); err != nil {
+			t.Fatalf("Fail() #%d error = %v", i+1, err)
+		}
+	}
+
+	if queue.Length() != 0 || queue.ProcessingLength() != 0 {
+		t.Errorf("Length() = %v, ProcessingLength() = %v, want 0 and 0", queue.Length(), queue.ProcessingLength())
+	}
+	if queue.DeadLength() != 1 {
+		t.Fatalf("DeadLength() = %v, want 1", queue.DeadLength())
+	}
+
+	dead, err := queue.DeadLetters()
+	if err != nil || len(dead) != 1 || dead[0].Name != "Alice" {
+		t.Errorf("DeadLetters() = %v, %v", dead, err)
+	}
+
+	// 放回后失败次数重置
+	requeued, err := queue.RequeueDead(0)
+	if err != nil || requeued != 1 {
+		t.Fatalf("RequeueDead() = %v, %v, want 1", requeued, err)
+	}
+	if queue.Length() != 1 || queue.DeadLength() != 0 {
+		t.Errorf("Length() = %v, DeadLength() = %v, want 1 and 0", queue.Length(), queue.DeadLength())
+	}
+
+	value, _ := queue.Take()
+	queue.Fail(value, errors.New("test error"))
+	if queue.DeadLength() != 0 || queue.Length() != 1 {
+		t.Errorf("Fail() after RequeueDead should retry, DeadLength() = %v, Length() = %v", queue.DeadLength(), queue.Length())
+	}
+}
+
+func TestQueue_FailDeadLetterWithStorage(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxRetry: 1,
+		ErrorHandler: func(value interface{}, err error, storage func(value interface{})) time.Duration {
+			s := value.(TestStruct)
+			s.Age++
+			storage(s)
+			return 0
+		},
+	}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+
+	value, _ := queue.Take()
+	queue.Fail(value, errors.New("test error"))
+
+	// 修改后的任务继承失败次数
+	value, ok := queue.Take()
+	if !ok || value.Age != 31 {
+		t.Fatalf("Take() after storage = %v, %v, want Age 31", value, ok)
+	}
+	queue.Fail(value, errors.New("test error"))
+
+	if queue.DeadLength() != 1 {
+		t.Errorf("DeadLength() = %v, want 1", queue.DeadLength())
+	}
+}
+
+func TestQueue_PurgeDead(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxRetry:          1,
+		VisibilityTimeout: time.Millisecond * 10,
+	}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+
+	// 两次处理超时后进入死信队列
+	for i := 0; i < 2; i++ {
+		if _, ok := queue.Take(); !ok {
+			t.Fatalf("Take() #%d returned false", i+1)
+		}
+		time.Sleep(time.Millisecond * 20)
+		queue.RequeueExpired()
+	}
+
+	if queue.DeadLength() != 1 {
+		t.Fatalf("DeadLength() = %v, want 1", queue.DeadLength())
+	}
+
+	if err := queue.PurgeDead(); err != nil {
+		t.Fatalf("PurgeDead() error = %v", err)
+	}
+	if queue.DeadLength() != 0 {
+		t.Errorf("DeadLength() after PurgeDead = %v, want 0", queue.DeadLength())
+	}
+}
+
+func TestQueue_AddWithPriority(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "normal1"})
+	queue.AddWithPriority(TestStruct{Name: "low"}, -1)
+	queue.AddWithPriority(TestStruct{Name: "high1"}, 10)
+	queue.AddWithPriority(TestStruct{Name: "urgent"}, 100)
+	queue.AddWithPriority(TestStruct{Name: "high2"}, 10)
+	queue.AddWithPriority(TestStruct{Name: "normal2"}, 0)
+
+	if length := queue.Length(); length != 6 {
+		t.Errorf("Length() = %v, want 6", length)
+	}
+
+	want := []string{"urgent", "high1", "high2", "normal1", "normal2", "low"}
+	for _, name := range want {
+		value, ok := queue.Take()
+		if !ok {
+			t.Fatalf("Take() returned false, want %v", name)
+		}
+		if value.Name != name {
+			t.Errorf("Take() Name = %v, want %v", value.Name, name)
+		}
+	}
+
+	if _, ok := queue.Take(); ok {
+		t.Error("Take() on empty queue should return false")
+	}
+	if length := queue.Length(); length != 0 {
+		t.Errorf("Length() after draining = %v, want 0", length)
+	}
+}
+
+func TestQueue_AddWithPriorityTracking(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxLength:   2,
+		MaxWaitTime: time.Millisecond * 100,
+		MaxRetry:    3,
+	}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "normal"})
+	queue.AddWithPriority(TestStruct{Name: "high"}, 5)
+	if err := queue.AddWithPriority(TestStruct{Name: "overflow"}, 5); err == nil {
+		t.Error("AddWithPriority() on full queue should return error")
+	}
+
+	value, ok := queue.Take()
+	if !ok || value.Name != "high" {
+		t.Fatalf("Take() = %v, %v, want high", value.Name, ok)
+	}
+	if queue.ProcessingLength() != 1 || queue.Length() != 1 {
+		t.Errorf("ProcessingLength() = %v, Length() = %v, want 1, 1", queue.ProcessingLength(), queue.Length())
+	}
+	queue.Complete(value)
+
+	queue.AddWithPriority(TestStruct{Name: "high"}, 5)
+	if err := queue.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if queue.Length() != 0 {
+		t.Errorf("Length() after Clear = %v, want 0", queue.Length())
+	}
+	keys, _ := tr.Redis.Do("KEYS", "*")
+	if keys, ok := keys.([]interface{}); !ok || len(keys) != 0 {
+		t.Errorf("keys after Clear = %v, want none", keys)
+	}
+}
+
+func TestQueue_BlockingTakeWithPriority(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxWaitTime: time.Second,
+	}, tr.Redis)
+
+	queue.Add(TestStruct{Name: "normal"})
+	queue.AddWithPriority(TestStruct{Name: "high"}, 1)
+
+	value, ok := queue.Take()
+	if !ok || value.Name != "high" {
+		t.Fatalf("Take() = %v, %v, want high", value.Name, ok)
+	}
+	value, ok = queue.Take()
+	if !ok || value.Name != "normal" {
+		t.Fatalf("Take() = %v, %v, want normal", value.Name, ok)
+	}
+}
+
+func TestQueue_RetryKeepsPriority(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	var retryDelay time.Duration
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxRetry:          5,
+		VisibilityTimeout: time.Millisecond * 50,
+		ErrorHandler: func(value interface{}, err error, storage func(value interface{})) time.Duration {
+			return retryDelay
+		},
+	}, tr.Redis)
+
+	takeUrgent := func(step string) TestStruct {
+		t.Helper()
+		value, ok := queue.Take()
+		if !ok || value.Name != "urgent" {
+			t.Fatalf("%s: Take() = %v, %v, want urgent", step, value.Name, ok)
+		}
+		return value
+	}
+
+	queue.Add(TestStruct{Name: "bulk1"})
+	queue.AddWithPriority(TestStruct{Name: "urgent"}, 10)
+	queue.Add(TestStruct{Name: "bulk2"})
+
+	// 立即重试回到原来的优先级
+	value := takeUrgent("first take")
+	queue.Fail(value, errors.New("test error"))
+
+	// 延迟重试到期后回到原来的优先级
+	value = takeUrgent("after immediate retry")
+	retryDelay = time.Millisecond * 10
+	queue.Fail(value, errors.New("test error"))
+	time.Sleep(time.Millisecond * 20)
+
+	// 处理超时放回后回到原来的优先级
+	takeUrgent("after delayed retry")
+	time.Sleep(time.Millisecond * 60)
+
+	value = takeUrgent("after visibility timeout")
+	if err := queue.Complete(value); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if exists, _ := tr.Redis.Do("EXISTS", queue.taskPriorities); exists.(int64) != 0 {
+		t.Error("task priority should be removed after Complete()")
+	}
+
+	if value, ok := queue.Take(); !ok || value.Name != "bulk1" {
+		t.Errorf("Take() = %v, %v, want bulk1", value.Name, ok)
+	}
+}
+
+func TestQueue_RequeueDeadKeepsPriority(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("testqueue", QueueConfig{
+		MaxRetry: 1,
+		ErrorHandler: func(value interface{}, err error, storage func(value interface{})) time.Duration {
+			return 0
+		},
+	}, tr.Redis)
+
+	queue.AddWithPriority(TestStruct{Name: "urgent"}, 10)
+	for i := 0; i < 2; i++ {
+		value, ok := queue.Take()
+		if !ok {
+			t.Fatalf("Take() #%d returned false", i+1)
+		}
+		queue.Fail(value, errors.New("test error"))
+	}
+	if queue.DeadLength() != 1 {
+		t.Fatalf("DeadLength() = %v, want 1", queue.DeadLength())
+	}
+
+	queue.Add(TestStruct{Name: "bulk"})
+	queue.RequeueDead(0)
+	for i := 0; i < 2; i++ {
+		value, ok := queue.Take()
+		if !ok || value.Name != "urgent" {
+			t.Fatalf("Take() #%d after RequeueDead() = %v, %v, want urgent", i+1, value.Name, ok)
+		}
+		queue.Fail(value, errors.New("test error"))
+	}
+
+	// 清空死信队列时删除任务的优先级
+	queue.PurgeDead()
+	if length, _ := redis.Int(tr.Redis.Do("HLEN", queue.taskPriorities)); length != 0 {
+		t.Errorf("task priorities after PurgeDead() = %d, want 0", length)
+	}
+}
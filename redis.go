@@ -12,6 +12,7 @@ import (
 type Redis struct {
 	pool   *redis.Pool
 	config Config
+	ctx    context.Context // 绑定的上下文，nil 表示不受上下文控制
 }
 
 // 全局默认连接
@@ -115,8 +116,17 @@ func (b *RedisBuilder) Build() *Redis {
 }
 
 // GetConn 获取连接
+// 如果通过 WithContext 绑定了上下文，获取连接和执行命令都会受该上下文的取消与超时控制
 func (r *Redis) GetConn() redis.Conn {
-	return r.pool.Get()
+	if r.ctx == nil {
+		return r.pool.Get()
+	}
+
+	conn, err := r.pool.GetContext(r.ctx)
+	if err != nil {
+		return errorConn{err: err}
+	}
+	return contextConn{Conn: conn, ctx: r.ctx}
 }
 
 // GetConnWithContext 获取带上下文的连接
@@ -124,6 +134,24 @@ func (r *Redis) GetConnWithContext(ctx context.Context) (redis.Conn, error) {
 	return r.pool.GetContext(ctx)
 }
 
+// WithContext 返回绑定了上下文的 Redis 客户端视图，与原客户端共享连接池
+func (r *Redis) WithContext(ctx context.Context) *Redis {
+	if ctx == nil {
+		panic("redisTool: nil context")
+	}
+	rc := *r
+	rc.ctx = ctx
+	return &rc
+}
+
+// Context 获取绑定的上下文，未绑定时返回 context.Background()
+func (r *Redis) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
 // Close 关闭连接池
 func (r *Redis) Close() error {
 	return r.pool.Close()
@@ -151,7 +179,40 @@ func (r *Redis) Do(commandName string, args ...interface{}) (interface{}, error)
 	return conn.Do(commandName, args...)
 }
 
+// DoContext 使用指定上下文执行 Redis 命令
+func (r *Redis) DoContext(ctx context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	return r.WithContext(ctx).Do(commandName, args...)
+}
+
 // DoWithConn 使用指定连接执行 Redis 命令
 func (r *Redis) DoWithConn(conn redis.Conn, commandName string, args ...interface{}) (interface{}, error) {
 	return conn.Do(commandName, args...)
 }
+
+// contextConn 绑定上下文的连接，Do 与 Receive 均受上下文控制
+type contextConn struct {
+	redis.Conn
+	ctx context.Context
+}
+
+// Do 执行命令
+func (c contextConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	return redis.DoContext(c.Conn, c.ctx, commandName, args...)
+}
+
+// Receive 接收回复
+func (c contextConn) Receive() (interface{}, error) {
+	return redis.ReceiveContext(c.Conn, c.ctx)
+}
+
+// errorConn 获取连接失败时返回的连接，所有操作均返回该错误
+type errorConn struct {
+	err error
+}
+
+func (c errorConn) Close() error                                   { return nil }
+func (c errorConn) Err() error                                     { return c.err }
+func (c errorConn) Do(string, ...interface{}) (interface{}, error) { return nil, c.err }
+func (c errorConn) Send(string, ...interface{}) error              { return c.err }
+func (c errorConn) Flush() error                                   { return c.err }
+func (c errorConn) Receive() (interface{}, error)                  { return nil, c.err }
@@ -1,6 +1,7 @@
 package redisTool
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
@@ -238,3 +239,52 @@ func TestDefaultNameCreator(t *testing.T) {
 		})
 	}
 }
+
+func TestRedis_WithContext(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := tr.Redis.WithContext(ctx)
+
+	if r.Context() != ctx {
+		t.Error("Context() should return the bound context")
+	}
+	if tr.Redis.Context() != context.Background() {
+		t.Error("Context() without binding should return context.Background()")
+	}
+
+	if _, err := r.Do("SET", "ctxkey", "value"); err != nil {
+		t.Fatalf("Do() with live context error = %v", err)
+	}
+
+	cancel()
+
+	if _, err := r.Do("GET", "ctxkey"); err == nil {
+		t.Error("Do() with cancelled context should return error")
+	}
+
+	// 原客户端不受影响
+	if _, err := tr.Redis.Do("GET", "ctxkey"); err != nil {
+		t.Errorf("Do() on original client error = %v", err)
+	}
+}
+
+func TestRedis_DoContext(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := tr.Redis.DoContext(ctx, "SET", "ctxkey", "value"); err != nil {
+		t.Fatalf("DoContext() error = %v", err)
+	}
+
+	expired, cancel2 := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel2()
+
+	if _, err := tr.Redis.DoContext(expired, "GET", "ctxkey"); err == nil {
+		t.Error("DoContext() with expired context should return error")
+	}
+}
@@ -0,0 +1,162 @@
+package redisTool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
+)
+
+// ReentrantLock 可重入分布式锁
+// 锁保存在哈希中，字段为持有者 ID，值为重入次数；同一个持有者可以重复获取，
+// 每次获取对应一次 Unlock，最后一次 Unlock 才真正释放锁。不能与同名的 Lock 混用
+type ReentrantLock struct {
+	redis  *Redis
+	name   string
+	owner  string
+	config LockConfig
+}
+
+// NewReentrantLock 创建可重入分布式锁，owner 为空时生成随机的持有者 ID
+// 使用相同 owner 创建的锁视为同一个持有者，可以在不同的函数甚至进程之间重入
+func (r *Redis) NewReentrantLock(name, owner string, config ...LockConfig) *ReentrantLock {
+	if owner == "" {
+		owner = uuid.New().String()
+	}
+	return &ReentrantLock{
+		redis:  r,
+		name:   r.CreateName(RedisTypeLock_, name),
+		owner:  owner,
+		config: newLockConfig(config...),
+	}
+}
+
+// WithContext 返回绑定了上下文的锁视图，与原锁是同一个持有者
+// 等待获取锁时上下文被取消会立即返回错误
+func (l *ReentrantLock) WithContext(ctx context.Context) *ReentrantLock {
+	return &ReentrantLock{
+		redis:  l.redis.WithContext(ctx),
+		name:   l.name,
+		owner:  l.owner,
+		config: l.config,
+	}
+}
+
+// Owner 获取持有者 ID
+func (l *ReentrantLock) Owner() string {
+	return l.owner
+}
+
+// Lock 获取锁，已由同一个持有者持有时增加重入次数并刷新过期时间
+func (l *ReentrantLock) Lock() error {
+	return acquireWithRetry(l.redis.Context(), l.config, l.TryLock)
+}
+
+// TryLock 尝试获取锁
+func (l *ReentrantLock) TryLock() bool {
+	// 锁不存在或由自己持有时增加重入次数
+	script := `
+		if redis.call("EXISTS", KEYS[1]) == 0 or redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1 then
+			local count = redis.call("HINCRBY", KEYS[1], ARGV[1], 1)
+			redis.call("PEXPIRE", KEYS[1], ARGV[2])
+			return count
+		end
+		return 0
+	`
+
+	conn := l.redis.GetConn()
+	defer conn.Close()
+
+	luaScript := redis.NewScript(1, script)
+	count, err := redis.Int(luaScript.Do(conn, l.name, l.owner, int(l.config.WaitTime.Milliseconds())))
+	return err == nil && count > 0
+}
+
+// Unlock 释放一次锁，重入次数减到 0 时删除锁
+func (l *ReentrantLock) Unlock() error {
+	script := `
+		if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 0 then
+			return -1
+		end
+		local count = redis.call("HINCRBY", KEYS[1], ARGV[1], -1)
+		if count <= 0 then
+			redis.call("DEL", KEYS[1])
+		end
+		return count
+	`
+
+	conn := l.redis.GetConn()
+	defer conn.Close()
+
+	luaScript := redis.NewScript(1, script)
+	count, err := redis.Int(luaScript.Do(conn, l.name, l.owner))
+	if err != nil {
+		return err
+	}
+
+	if count < 0 {
+		return fmt.Errorf("unlock failed: lock not held by this owner")
+	}
+	return nil
+}
+
+// LockFunc 使用闭包简化锁的使用
+func (l *ReentrantLock) LockFunc(fn func()) error {
+	if err := l.Lock(); err != nil {
+		return err
+	}
+	defer l.Unlock()
+
+	fn()
+	return nil
+}
+
+// TryLockFunc 尝试使用闭包简化锁的使用
+func (l *ReentrantLock) TryLockFunc(fn func()) bool {
+	if !l.TryLock() {
+		return false
+	}
+	defer l.Unlock()
+
+	fn()
+	return true
+}
+
+// IsLocked 检查锁是否由自己持有
+func (l *ReentrantLock) IsLocked() bool {
+	return l.HoldCount() > 0
+}
+
+// HoldCount 获取自己持有锁的重入次数，未持有时返回 0
+func (l *ReentrantLock) HoldCount() int {
+	count, err := redis.Int(l.redis.Do("HGET", l.name, l.owner))
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+// Refresh 刷新锁的过期时间，所有重入共享同一个过期时间
+func (l *ReentrantLock) Refresh() error {
+	script := `
+		if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1 then
+			return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+		end
+		return 0
+	`
+
+	conn := l.redis.GetConn()
+	defer conn.Close()
+
+	luaScript := redis.NewScript(1, script)
+	result, err := redis.Int(luaScript.Do(conn, l.name, l.owner, int(l.config.WaitTime.Milliseconds())))
+	if err != nil {
+		return err
+	}
+
+	if result == 0 {
+		return fmt.Errorf("refresh failed: lock not held by this owner")
+	}
+	return nil
+}
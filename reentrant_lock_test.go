@@ -0,0 +1,118 @@
+package redisTool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReentrantLock_Reenter(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	lock := tr.Redis.NewReentrantLock("testlock", "owner1", LockConfig{MaxGetLockWaitTime: 0})
+	other := tr.Redis.NewReentrantLock("testlock", "owner2", LockConfig{MaxGetLockWaitTime: 0})
+
+	if err := lock.Lock(); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	// 同一个持有者再次获取不会阻塞
+	if err := lock.Lock(); err != nil {
+		t.Fatalf("Lock() again error = %v", err)
+	}
+	if !tr.Redis.NewReentrantLock("testlock", "owner1").TryLock() {
+		t.Fatal("TryLock() by the same owner should succeed")
+	}
+	if count := lock.HoldCount(); count != 3 {
+		t.Errorf("HoldCount() = %d, want 3", count)
+	}
+
+	if other.TryLock() {
+		t.Fatal("TryLock() by another owner should fail")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := lock.Unlock(); err != nil {
+			t.Fatalf("Unlock() error = %v", err)
+		}
+	}
+	if !lock.IsLocked() {
+		t.Error("IsLocked() = false before the final Unlock()")
+	}
+	if other.TryLock() {
+		t.Fatal("TryLock() by another owner should fail before the final Unlock()")
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if lock.IsLocked() {
+		t.Error("IsLocked() = true after the final Unlock()")
+	}
+	if err := lock.Unlock(); err == nil {
+		t.Error("Unlock() when not held should fail")
+	}
+
+	if !other.TryLock() {
+		t.Error("TryLock() by another owner after release should succeed")
+	}
+	other.Unlock()
+}
+
+func TestReentrantLock_LockFunc(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	lock := tr.Redis.NewReentrantLock("testlock", "", LockConfig{MaxGetLockWaitTime: 0})
+
+	called := false
+	err := lock.LockFunc(func() {
+		// 在持有锁的函数中调用同样加锁的函数
+		called = lock.TryLockFunc(func() {})
+	})
+	if err != nil {
+		t.Fatalf("LockFunc() error = %v", err)
+	}
+	if !called {
+		t.Error("nested TryLockFunc() should succeed")
+	}
+	if lock.IsLocked() {
+		t.Error("IsLocked() = true after LockFunc() returns")
+	}
+	if lock.Owner() == "" {
+		t.Error("Owner() should be generated when empty")
+	}
+}
+
+func TestReentrantLock_Refresh(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	lock := tr.Redis.NewReentrantLock("testlock", "owner1", LockConfig{
+		WaitTime: time.Second * 2,
+	})
+
+	if err := lock.Refresh(); err == nil {
+		t.Error("Refresh() when not held should fail")
+	}
+
+	lock.Lock()
+	lock.Lock()
+
+	tr.FastForward(1)
+	if err := lock.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	tr.FastForward(1)
+	if count := lock.HoldCount(); count != 2 {
+		t.Errorf("HoldCount() after Refresh() = %d, want 2", count)
+	}
+
+	// 不刷新时锁整体过期，包括所有重入
+	tr.FastForward(3)
+	if lock.IsLocked() {
+		t.Error("IsLocked() = true after expiration")
+	}
+	if !tr.Redis.NewReentrantLock("testlock", "owner2").TryLock() {
+		t.Error("TryLock() by another owner after expiration should succeed")
+	}
+}
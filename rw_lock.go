@@ -0,0 +1,219 @@
+package redisTool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
+)
+
+// RWLock 分布式读写锁
+// 读锁可以被多个持有者同时持有，写锁独占；每个 RWLock 实例代表一个持有者，同一时间只持有读锁或写锁之一。
+// 读者记录在哈希中，每个读者有独立的过期时间（WaitTime），崩溃的读者过期后不再阻塞写者；
+// 写者在 Lock 中等待时设置等待标记，阻止新的读者获取读锁，避免写者饥饿
+type RWLock struct {
+	redis       *Redis
+	writerName  string
+	readersName string
+	waitingName string
+	token       string
+	config      LockConfig
+}
+
+// NewRWLock 创建分布式读写锁
+func (r *Redis) NewRWLock(name string, config ...LockConfig) *RWLock {
+	baseName := r.CreateName(RedisTypeLock_, name)
+	return &RWLock{
+		redis:       r,
+		writerName:  baseName + ":writer",
+		readersName: baseName + ":readers",
+		waitingName: baseName + ":waiting",
+		token:       uuid.New().String(),
+		config:      newLockConfig(config...),
+	}
+}
+
+// WithContext 返回绑定了上下文的锁视图，与原锁是同一个持有者
+// 等待获取锁时上下文被取消会立即返回错误
+func (l *RWLock) WithContext(ctx context.Context) *RWLock {
+	return &RWLock{
+		redis:       l.redis.WithContext(ctx),
+		writerName:  l.writerName,
+		readersName: l.readersName,
+		waitingName: l.waitingName,
+		token:       l.token,
+		config:      l.config,
+	}
+}
+
+// RLock 获取读锁，有写者持有或等待写锁时等待
+func (l *RWLock) RLock() error {
+	return acquireWithRetry(l.redis.Context(), l.config, l.TryRLock)
+}
+
+// TryRLock 尝试获取读锁
+func (l *RWLock) TryRLock() bool {
+	script := lockNowScript + `
+		if redis.call("EXISTS", KEYS[1]) == 1 or redis.call("EXISTS", KEYS[3]) == 1 then
+			return 0
+		end
+		local ttl = tonumber(ARGV[2])
+		redis.call("HSET", KEYS[2], ARGV[1], now + ttl)
+		-- 哈希的过期时间取所有读者中最晚的
+		if redis.call("PTTL", KEYS[2]) < ttl then
+			redis.call("PEXPIRE", KEYS[2], ttl)
+		end
+		return 1
+	`
+
+	result, err := redis.Int(l.runScript(script, int(l.config.WaitTime.Milliseconds())))
+	return err == nil && result == 1
+}
+
+// RUnlock 释放读锁
+func (l *RWLock) RUnlock() error {
+	removed, err := redis.Int(l.redis.Do("HDEL", l.readersName, l.token))
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return fmt.Errorf("unlock failed: read lock not held by this instance")
+	}
+	return nil
+}
+
+// Lock 获取写锁，等待期间阻止新的读者获取读锁
+func (l *RWLock) Lock() error {
+	return acquireWithRetry(l.redis.Context(), l.config, func() bool {
+		return l.tryLock(true)
+	})
+}
+
+// TryLock 尝试获取写锁
+func (l *RWLock) TryLock() bool {
+	return l.tryLock(false)
+}
+
+// tryLock 尝试获取写锁，wait 为 true 时获取失败后设置等待标记
+func (l *RWLock) tryLock(wait bool) bool {
+	script := lockNowScript + `
+		-- 清理已过期的读者
+		local readers = redis.call("HGETALL", KEYS[2])
+		for i = 1, #readers, 2 do
+			if tonumber(readers[i + 1]) <= now then
+				redis.call("HDEL", KEYS[2], readers[i])
+			end
+		end
+
+		local waiting = redis.call("GET", KEYS[3])
+		if redis.call("EXISTS", KEYS[1]) == 0 and redis.call("HLEN", KEYS[2]) == 0 then
+			redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+			if waiting == ARGV[1] then
+				redis.call("DEL", KEYS[3])
+			end
+			return 1
+		end
+
+		-- 等待标记只由一个写者持有，过期前需要在每次重试时续期
+		if ARGV[3] == "1" and (not waiting or waiting == ARGV[1]) then
+			redis.call("SET", KEYS[3], ARGV[1], "PX", ARGV[4])
+		end
+		return 0
+	`
+
+	waitFlag := "0"
+	if wait {
+		waitFlag = "1"
+	}
+	result, err := redis.Int(l.runScript(script, int(l.config.WaitTime.Milliseconds()), waitFlag,
+		int(l.waitingTime().Milliseconds())))
+	return err == nil && result == 1
+}
+
+// Unlock 释放写锁
+func (l *RWLock) Unlock() error {
+	script := `
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("DEL", KEYS[1])
+		end
+		return 0
+	`
+
+	result, err := redis.Int(l.runScript(script))
+	if err != nil {
+		return err
+	}
+
+	if result == 0 {
+		return fmt.Errorf("unlock failed: write lock not held by this instance")
+	}
+	return nil
+}
+
+// RLockFunc 持有读锁执行闭包
+func (l *RWLock) RLockFunc(fn func()) error {
+	if err := l.RLock(); err != nil {
+		return err
+	}
+	defer l.RUnlock()
+
+	fn()
+	return nil
+}
+
+// LockFunc 持有写锁执行闭包
+func (l *RWLock) LockFunc(fn func()) error {
+	if err := l.Lock(); err != nil {
+		return err
+	}
+	defer l.Unlock()
+
+	fn()
+	return nil
+}
+
+// Refresh 刷新自己持有的读锁或写锁的过期时间
+func (l *RWLock) Refresh() error {
+	script := lockNowScript + `
+		local ttl = tonumber(ARGV[2])
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("PEXPIRE", KEYS[1], ttl)
+		end
+		local expireAt = redis.call("HGET", KEYS[2], ARGV[1])
+		if expireAt and tonumber(expireAt) > now then
+			redis.call("HSET", KEYS[2], ARGV[1], now + ttl)
+			if redis.call("PTTL", KEYS[2]) < ttl then
+				redis.call("PEXPIRE", KEYS[2], ttl)
+			end
+			return 1
+		end
+		return 0
+	`
+
+	result, err := redis.Int(l.runScript(script, int(l.config.WaitTime.Milliseconds())))
+	if err != nil {
+		return err
+	}
+
+	if result == 0 {
+		return fmt.Errorf("refresh failed: lock not held by this instance")
+	}
+	return nil
+}
+
+// runScript 执行脚本，KEYS 为写锁、读者哈希和等待标记，ARGV[1] 为持有者 token
+func (l *RWLock) runScript(script string, args ...interface{}) (interface{}, error) {
+	conn := l.redis.GetConn()
+	defer conn.Close()
+
+	luaScript := redis.NewScript(3, script)
+	return luaScript.Do(conn, append([]interface{}{l.writerName, l.readersName, l.waitingName, l.token}, args...)...)
+}
+
+// waitingTime 等待标记的存活时间，写者停止重试后标记很快过期，不再阻塞读者
+func (l *RWLock) waitingTime() time.Duration {
+	return l.config.RetryTime * 2
+}
@@ -0,0 +1,177 @@
+package redisTool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRWLock_SharedReaders(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	config := LockConfig{MaxGetLockWaitTime: 0}
+	reader1 := tr.Redis.NewRWLock("testlock", config)
+	reader2 := tr.Redis.NewRWLock("testlock", config)
+	writer := tr.Redis.NewRWLock("testlock", config)
+
+	if !reader1.TryRLock() || !reader2.TryRLock() {
+		t.Fatal("TryRLock() by multiple readers should succeed")
+	}
+	if writer.TryLock() {
+		t.Fatal("TryLock() should fail while readers hold the lock")
+	}
+
+	reader1.RUnlock()
+	if writer.TryLock() {
+		t.Fatal("TryLock() should fail while a reader holds the lock")
+	}
+	if err := reader2.RUnlock(); err != nil {
+		t.Fatalf("RUnlock() error = %v", err)
+	}
+	if err := reader2.RUnlock(); err == nil {
+		t.Error("RUnlock() when not held should fail")
+	}
+
+	if !writer.TryLock() {
+		t.Fatal("TryLock() after all readers released should succeed")
+	}
+	if reader1.TryRLock() {
+		t.Error("TryRLock() should fail while the writer holds the lock")
+	}
+	if tr.Redis.NewRWLock("testlock", config).TryLock() {
+		t.Error("TryLock() by another writer should fail")
+	}
+
+	if err := writer.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if err := writer.Unlock(); err == nil {
+		t.Error("Unlock() when not held should fail")
+	}
+	if !reader1.TryRLock() {
+		t.Error("TryRLock() after the writer released should succeed")
+	}
+}
+
+func TestRWLock_ReaderExpire(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	// 读者持有读锁后崩溃，不释放
+	crashed := tr.Redis.NewRWLock("testlock", LockConfig{WaitTime: time.Millisecond * 100})
+	if !crashed.TryRLock() {
+		t.Fatal("TryRLock() should succeed")
+	}
+
+	writer := tr.Redis.NewRWLock("testlock", LockConfig{MaxGetLockWaitTime: 0})
+	if writer.TryLock() {
+		t.Fatal("TryLock() should fail while the reader is alive")
+	}
+
+	time.Sleep(time.Millisecond * 150)
+	if !writer.TryLock() {
+		t.Error("TryLock() after the reader expired should succeed")
+	}
+	if err := crashed.Refresh(); err == nil {
+		t.Error("Refresh() by an expired reader should fail")
+	}
+}
+
+func TestRWLock_ServerTime(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+	if tr.MiniRedis == nil {
+		t.Skip("server clock test requires miniredis")
+	}
+
+	// 服务器时间比客户端慢一小时，按服务器时间仍有效的读者不能被客户端时间提前清理
+	serverNow := time.Now().Add(-time.Hour)
+	tr.MiniRedis.SetTime(serverNow)
+
+	reader := tr.Redis.NewRWLock("testlock", LockConfig{WaitTime: time.Minute})
+	if _, err := tr.Redis.Do("HSET", reader.readersName, reader.token, serverNow.Add(time.Minute).UnixMilli()); err != nil {
+		t.Fatalf("HSET error = %v", err)
+	}
+
+	writer := tr.Redis.NewRWLock("testlock", LockConfig{MaxGetLockWaitTime: 0})
+	if writer.TryLock() {
+		t.Error("TryLock() should fail while the reader is valid on the server clock")
+	}
+	if err := reader.Refresh(); err != nil {
+		t.Errorf("Refresh() error = %v", err)
+	}
+}
+
+func TestRWLock_WriterPreference(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	reader := tr.Redis.NewRWLock("testlock")
+	if err := reader.RLock(); err != nil {
+		t.Fatalf("RLock() error = %v", err)
+	}
+
+	writer := tr.Redis.NewRWLock("testlock", LockConfig{
+		RetryTime:          time.Millisecond * 50,
+		MaxGetLockWaitTime: time.Second * 5,
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	acquired := make(chan struct{})
+	go func() {
+		defer wg.Done()
+		if err := writer.Lock(); err != nil {
+			t.Errorf("Lock() error = %v", err)
+			return
+		}
+		close(acquired)
+	}()
+
+	// 写者等待期间新的读者不能获取读锁
+	time.Sleep(time.Millisecond * 100)
+	if tr.Redis.NewRWLock("testlock").TryRLock() {
+		t.Error("TryRLock() should fail while a writer is waiting")
+	}
+
+	reader.RUnlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("writer should acquire the lock after the reader released")
+	}
+	wg.Wait()
+
+	writer.Unlock()
+	if !tr.Redis.NewRWLock("testlock").TryRLock() {
+		t.Error("TryRLock() after the writer released should succeed")
+	}
+}
+
+func TestRWLock_Refresh(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	lock := tr.Redis.NewRWLock("testlock", LockConfig{WaitTime: time.Second * 2})
+	if err := lock.Refresh(); err == nil {
+		t.Error("Refresh() when not held should fail")
+	}
+
+	lock.Lock()
+	tr.FastForward(1)
+	if err := lock.Refresh(); err != nil {
+		t.Fatalf("Refresh() write lock error = %v", err)
+	}
+	tr.FastForward(1)
+	if lock.TryRLock() {
+		t.Error("write lock should still be held after Refresh()")
+	}
+	lock.Unlock()
+
+	lock.RLock()
+	if err := lock.Refresh(); err != nil {
+		t.Errorf("Refresh() read lock error = %v", err)
+	}
+	lock.RUnlock()
+}
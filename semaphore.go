@@ -0,0 +1,146 @@
+package redisTool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
+)
+
+// Semaphore 分布式计数信号量，最多允许 permits 个持有者同时持有
+// 持有者记录在有序集合中，分数为租约到期时间（WaitTime），崩溃的持有者到期后自动释放许可；
+// 每个 Semaphore 实例代表一个持有者，并发使用时每个调用创建一个实例
+type Semaphore struct {
+	redis   *Redis
+	name    string
+	token   string
+	permits int
+	config  LockConfig
+}
+
+// NewSemaphore 创建分布式信号量，permits 小于 1 时按 1 处理
+// config 与锁相同：WaitTime 为租约时间，RetryTime 和 MaxGetLockWaitTime 控制 Acquire 的重试和最长等待时间
+func (r *Redis) NewSemaphore(name string, permits int, config ...LockConfig) *Semaphore {
+	if permits < 1 {
+		permits = 1
+	}
+	return &Semaphore{
+		redis:   r,
+		name:    r.CreateName(RedisTypeLock_, name) + ":semaphore",
+		token:   uuid.New().String(),
+		permits: permits,
+		config:  newLockConfig(config...),
+	}
+}
+
+// Acquire 获取许可，没有空闲许可时按 RetryTime 重试，ctx 取消或超过 MaxGetLockWaitTime 时返回错误
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	r := s.redis.WithContext(ctx)
+	return acquireWithRetry(ctx, s.config, func() bool {
+		return s.tryAcquire(r)
+	})
+}
+
+// TryAcquire 尝试获取许可，已持有时续期租约
+func (s *Semaphore) TryAcquire() bool {
+	return s.tryAcquire(s.redis)
+}
+
+// tryAcquire 清理到期的持有者后尝试获取许可
+func (s *Semaphore) tryAcquire(r *Redis) bool {
+	script := lockNowScript + `
+		local ttl = tonumber(ARGV[2])
+		redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+		if not redis.call("ZSCORE", KEYS[1], ARGV[1]) and redis.call("ZCARD", KEYS[1]) >= tonumber(ARGV[3]) then
+			return 0
+		end
+		redis.call("ZADD", KEYS[1], now + ttl, ARGV[1])
+		-- 有序集合的过期时间取所有租约中最晚的
+		if redis.call("PTTL", KEYS[1]) < ttl then
+			redis.call("PEXPIRE", KEYS[1], ttl)
+		end
+		return 1
+	`
+
+	conn := r.GetConn()
+	defer conn.Close()
+
+	luaScript := redis.NewScript(1, script)
+	result, err := redis.Int(luaScript.Do(conn, s.name, s.token, int(s.config.WaitTime.Milliseconds()), s.permits))
+	return err == nil && result == 1
+}
+
+// Release 释放许可
+func (s *Semaphore) Release() error {
+	removed, err := redis.Int(s.redis.Do("ZREM", s.name, s.token))
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return fmt.Errorf("release failed: permit not held by this instance")
+	}
+	return nil
+}
+
+// AcquireFunc 持有许可执行闭包
+func (s *Semaphore) AcquireFunc(ctx context.Context, fn func()) error {
+	if err := s.Acquire(ctx); err != nil {
+		return err
+	}
+	defer s.Release()
+
+	fn()
+	return nil
+}
+
+// Refresh 续期自己持有的许可的租约
+func (s *Semaphore) Refresh() error {
+	script := lockNowScript + `
+		local ttl = tonumber(ARGV[2])
+		local expireAt = redis.call("ZSCORE", KEYS[1], ARGV[1])
+		if not expireAt or tonumber(expireAt) <= now then
+			return 0
+		end
+		redis.call("ZADD", KEYS[1], now + ttl, ARGV[1])
+		if redis.call("PTTL", KEYS[1]) < ttl then
+			redis.call("PEXPIRE", KEYS[1], ttl)
+		end
+		return 1
+	`
+
+	conn := s.redis.GetConn()
+	defer conn.Close()
+
+	luaScript := redis.NewScript(1, script)
+	result, err := redis.Int(luaScript.Do(conn, s.name, s.token, int(s.config.WaitTime.Milliseconds())))
+	if err != nil {
+		return err
+	}
+
+	if result == 0 {
+		return fmt.Errorf("refresh failed: permit not held by this instance")
+	}
+	return nil
+}
+
+// Available 获取当前空闲的许可数量
+func (s *Semaphore) Available() int {
+	script := lockNowScript + `
+		return redis.call("ZCOUNT", KEYS[1], string.format("(%d", now), "+inf")
+	`
+
+	conn := s.redis.GetConn()
+	defer conn.Close()
+
+	luaScript := redis.NewScript(1, script)
+	held, err := redis.Int(luaScript.Do(conn, s.name))
+	if err != nil {
+		return 0
+	}
+	if held >= s.permits {
+		return 0
+	}
+	return s.permits - held
+}
@@ -0,0 +1,162 @@
+package redisTool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSemaphore_TryAcquire(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	sem1 := tr.Redis.NewSemaphore("testsem", 2)
+	sem2 := tr.Redis.NewSemaphore("testsem", 2)
+	sem3 := tr.Redis.NewSemaphore("testsem", 2)
+
+	if available := sem1.Available(); available != 2 {
+		t.Errorf("Available() = %d, want 2", available)
+	}
+	if !sem1.TryAcquire() || !sem2.TryAcquire() {
+		t.Fatal("TryAcquire() within permits should succeed")
+	}
+	// 已持有时再次获取只续期，不占用新的许可
+	if !sem1.TryAcquire() {
+		t.Error("TryAcquire() by a holder should succeed")
+	}
+	if sem3.TryAcquire() {
+		t.Fatal("TryAcquire() beyond permits should fail")
+	}
+	if available := sem1.Available(); available != 0 {
+		t.Errorf("Available() = %d, want 0", available)
+	}
+
+	if err := sem1.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if err := sem1.Release(); err == nil {
+		t.Error("Release() when not held should fail")
+	}
+	if !sem3.TryAcquire() {
+		t.Error("TryAcquire() after Release() should succeed")
+	}
+}
+
+func TestSemaphore_LeaseExpire(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	// 持有者获取许可后崩溃，不释放
+	crashed := tr.Redis.NewSemaphore("testsem", 1, LockConfig{WaitTime: time.Millisecond * 100})
+	if !crashed.TryAcquire() {
+		t.Fatal("TryAcquire() should succeed")
+	}
+
+	sem := tr.Redis.NewSemaphore("testsem", 1)
+	if sem.TryAcquire() {
+		t.Fatal("TryAcquire() should fail while the lease is valid")
+	}
+
+	time.Sleep(time.Millisecond * 150)
+	if !sem.TryAcquire() {
+		t.Error("TryAcquire() after the lease expired should succeed")
+	}
+	if err := crashed.Refresh(); err == nil {
+		t.Error("Refresh() after the lease expired should fail")
+	}
+	if err := sem.Refresh(); err != nil {
+		t.Errorf("Refresh() error = %v", err)
+	}
+}
+
+func TestSemaphore_ServerTime(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+	if tr.MiniRedis == nil {
+		t.Skip("server clock test requires miniredis")
+	}
+
+	// 服务器时间比客户端慢一小时，按服务器时间仍有效的租约不能被客户端时间提前清理
+	serverNow := time.Now().Add(-time.Hour)
+	tr.MiniRedis.SetTime(serverNow)
+
+	holder := tr.Redis.NewSemaphore("testsem", 1, LockConfig{WaitTime: time.Minute})
+	if _, err := tr.Redis.Do("ZADD", holder.name, serverNow.Add(time.Minute).UnixMilli(), holder.token); err != nil {
+		t.Fatalf("ZADD error = %v", err)
+	}
+
+	sem := tr.Redis.NewSemaphore("testsem", 1)
+	if sem.TryAcquire() {
+		t.Error("TryAcquire() should fail while the lease is valid on the server clock")
+	}
+	if available := sem.Available(); available != 0 {
+		t.Errorf("Available() = %d, want 0", available)
+	}
+	if err := holder.Refresh(); err != nil {
+		t.Errorf("Refresh() error = %v", err)
+	}
+}
+
+func TestSemaphore_Acquire(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	const permits = 3
+	var running, maxRunning atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem := tr.Redis.NewSemaphore("testsem", permits, LockConfig{
+				RetryTime:          time.Millisecond * 10,
+				MaxGetLockWaitTime: time.Second * 5,
+			})
+			err := sem.AcquireFunc(context.Background(), func() {
+				n := running.Add(1)
+				for {
+					peak := maxRunning.Load()
+					if n <= peak || maxRunning.CompareAndSwap(peak, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond * 20)
+				running.Add(-1)
+			})
+			if err != nil {
+				t.Errorf("AcquireFunc() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak := maxRunning.Load(); peak > permits {
+		t.Errorf("max concurrent holders = %d, want at most %d", peak, permits)
+	}
+}
+
+func TestSemaphore_AcquireContext(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	holder := tr.Redis.NewSemaphore("testsem", 1)
+	holder.TryAcquire()
+
+	sem := tr.Redis.NewSemaphore("testsem", 1, LockConfig{
+		RetryTime:          time.Millisecond * 50,
+		MaxGetLockWaitTime: time.Second * 10,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+
+	start := time.Now()
+	if err := sem.Acquire(ctx); err == nil {
+		t.Fatal("Acquire() should fail when context is done")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Acquire() returned after %v, want it to stop with the context", time.Since(start))
+	}
+}
@@ -1,6 +1,7 @@
 package redisTool
 
 import (
+	"context"
 	"reflect"
 
 	"github.com/gomodule/redigo/redis"
@@ -25,6 +26,19 @@ func (r *Redis) NewSet(name string) *RedisSet {
 	}
 }
 
+// WithContext 返回绑定了上下文的集合视图
+func (s *RedisSet) WithContext(ctx context.Context) *RedisSet {
+	return &RedisSet{
+		redis: s.redis.WithContext(ctx),
+		name:  s.name,
+	}
+}
+
+
+// Name 获取集合的 Redis 键名
+func (s *RedisSet) Name() string {
+	return s.name
+}
 
 // Add 添加元素
 func (s *RedisSet) Add(values ...interface{}) error {
@@ -125,6 +139,34 @@ func (s *RedisSet) ToArray() ([]interface{}, error) {
 	return result, nil
 }
 
+// SendAdd 将添加元素的命令排入管道或事务
+func (s *RedisSet) SendAdd(sender Sender, values ...interface{}) (*Result, error) {
+	args := make([]interface{}, 0, len(values)+1)
+	args = append(args, s.name)
+	for _, value := range values {
+		data, err := s.redis.Serialize(value)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, data)
+	}
+	return sender.Send("SADD", args...), nil
+}
+
+// SendRemove 将移除元素的命令排入管道或事务
+func (s *RedisSet) SendRemove(sender Sender, values ...interface{}) (*Result, error) {
+	args := make([]interface{}, 0, len(values)+1)
+	args = append(args, s.name)
+	for _, value := range values {
+		data, err := s.redis.Serialize(value)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, data)
+	}
+	return sender.Send("SREM", args...), nil
+}
+
 // Iterator 获取迭代器
 func (s *RedisSet) Iterator(batchSize int) <-chan interface{} {
 	ch := make(chan interface{})
@@ -165,6 +207,18 @@ func (s *RedisSet) Iterator(batchSize int) <-chan interface{} {
 
 // === RedisTypeSet 类型化方法 ===
 
+// WithContext 返回绑定了上下文的集合视图
+func (ts *RedisTypeSet[T]) WithContext(ctx context.Context) *RedisTypeSet[T] {
+	return &RedisTypeSet[T]{
+		set: ts.set.WithContext(ctx),
+	}
+}
+
+// Name 获取集合的 Redis 键名
+func (ts *RedisTypeSet[T]) Name() string {
+	return ts.set.Name()
+}
+
 // Add 添加元素
 func (ts *RedisTypeSet[T]) Add(values ...T) error {
 	if len(values) == 0 {
@@ -239,6 +293,24 @@ func (ts *RedisTypeSet[T]) ToArray() ([]T, error) {
 	return result, nil
 }
 
+// SendAdd 将添加元素的命令排入管道或事务
+func (ts *RedisTypeSet[T]) SendAdd(sender Sender, values ...T) (*Result, error) {
+	interfaces := make([]interface{}, len(values))
+	for i, v := range values {
+		interfaces[i] = v
+	}
+	return ts.set.SendAdd(sender, interfaces...)
+}
+
+// SendRemove 将移除元素的命令排入管道或事务
+func (ts *RedisTypeSet[T]) SendRemove(sender Sender, values ...T) (*Result, error) {
+	interfaces := make([]interface{}, len(values))
+	for i, v := range values {
+		interfaces[i] = v
+	}
+	return ts.set.SendRemove(sender, interfaces...)
+}
+
 // Iterator 获取迭代器
 func (ts *RedisTypeSet[T]) Iterator(batchSize int) <-chan T {
 	ch := make(chan T)
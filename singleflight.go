@@ -0,0 +1,89 @@
+package redisTool
+
+import "sync"
+
+// flightGroup 进程内的单飞调用组，同一个键同时只执行一次 fn，其他调用者等待并共享结果
+type flightGroup[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[T]
+}
+
+// flightCall 执行中的调用
+type flightCall[T any] struct {
+	done      chan struct{}
+	value     T
+	panicked  bool
+	recovered interface{}
+}
+
+// newFlightGroup 创建单飞调用组
+func newFlightGroup[T any]() *flightGroup[T] {
+	return &flightGroup[T]{
+		calls: make(map[string]*flightCall[T]),
+	}
+}
+
+// do 执行 fn 并返回结果，同一个键的并发调用共享同一次执行
+// fn panic 时所有调用者都会以相同的值 panic
+func (g *flightGroup[T]) do(key string, fn func() T) T {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-call.done
+		if call.panicked {
+			panic(call.recovered)
+		}
+		return call.value
+	}
+
+	call := &flightCall[T]{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			call.panicked, call.recovered = true, r
+		}
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+
+		if call.panicked {
+			panic(call.recovered)
+		}
+	}()
+
+	call.value = fn()
+	return call.value
+}
+
+// doAsync 在后台执行 fn，同一个键已有调用在执行时直接返回
+func (g *flightGroup[T]) doAsync(key string, fn func() T) {
+	g.mu.Lock()
+	if _, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		return
+	}
+
+	call := &flightCall[T]{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	go func() {
+		defer func() {
+			// 后台调用的 panic 只传递给等待的调用者，不让进程崩溃
+			if r := recover(); r != nil {
+				call.panicked, call.recovered = true, r
+			}
+
+			g.mu.Lock()
+			delete(g.calls, key)
+			g.mu.Unlock()
+			close(call.done)
+		}()
+
+		call.value = fn()
+	}()
+}
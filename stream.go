@@ -0,0 +1,428 @@
+package redisTool
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
+)
+
+// streamDataField 流消息中存放序列化数据的字段名
+const streamDataField = "data"
+
+// StreamQueue 基于 Redis Streams 和消费组的队列
+// 消息在确认（Complete）之前一直保留在消费组的待处理列表中，消费者崩溃后可由其他消费者认领，不会丢失
+type StreamQueue[T any] struct {
+	redis      *Redis
+	name       string
+	deadName   string // 无法解析的消息移入的流
+	config     StreamQueueConfig
+	groupReady *atomic.Bool
+}
+
+// StreamMessage 流队列消息
+type StreamMessage[T any] struct {
+	ID         string // 消息 ID
+	Value      T      // 消息内容
+	Deliveries int    // 投递次数
+}
+
+// StreamPendingEntry 待处理消息信息
+type StreamPendingEntry struct {
+	ID         string        // 消息 ID
+	Consumer   string        // 当前持有消息的消费者
+	Idle       time.Duration // 距上次投递的时间
+	Deliveries int           // 投递次数
+}
+
+// Name 获取 Redis 流的键名
+func (q *StreamQueue[T]) Name() string {
+	return q.name
+}
+
+// WithContext 返回绑定了上下文的队列视图，阻塞的 Take 也会随上下文取消而返回
+func (q *StreamQueue[T]) WithContext(ctx context.Context) *StreamQueue[T] {
+	qc := *q
+	qc.redis = q.redis.WithContext(ctx)
+	return &qc
+}
+
+// Add 添加消息到队列
+func (q *StreamQueue[T]) Add(value T) error {
+	data, err := q.redis.Serialize(value)
+	if err != nil {
+		return err
+	}
+
+	args := []interface{}{q.name}
+	if q.config.MaxLength > 0 {
+		args = append(args, "MAXLEN", "~", q.config.MaxLength)
+	}
+	args = append(args, "*", streamDataField, data)
+
+	_, err = q.redis.Do("XADD", args...)
+	return err
+}
+
+// Take 获取消息
+// 优先认领其他消费者超过 ClaimIdle 仍未确认的消息，然后读取新消息
+func (q *StreamQueue[T]) Take() (StreamMessage[T], bool) {
+	if err := q.ensureGroup(); err != nil {
+		return StreamMessage[T]{}, false
+	}
+
+	if q.config.ClaimIdle > 0 {
+		if msg, ok := q.claimOne(); ok {
+			return msg, true
+		}
+	}
+
+	args := []interface{}{"GROUP", q.config.Group, q.config.Consumer, "COUNT", 1}
+	if q.config.MaxWaitTime > 0 {
+		args = append(args, "BLOCK", q.config.MaxWaitTime.Milliseconds())
+	}
+	args = append(args, "STREAMS", q.name, ">")
+
+	reply, err := q.readGroup(args)
+	if err != nil || reply == nil {
+		return StreamMessage[T]{}, false
+	}
+
+	// 回复格式：[[stream, [[id, [field, value, ...]], ...]]]
+	streams, err := redis.Values(reply, nil)
+	if err != nil || len(streams) == 0 {
+		return StreamMessage[T]{}, false
+	}
+	stream, err := redis.Values(streams[0], nil)
+	if err != nil || len(stream) != 2 {
+		return StreamMessage[T]{}, false
+	}
+	entries, err := redis.Values(stream[1], nil)
+	if err != nil || len(entries) == 0 {
+		return StreamMessage[T]{}, false
+	}
+
+	msg, ok := q.parseEntry(entries[0])
+	if !ok {
+		q.discardEntry(entries[0])
+		return StreamMessage[T]{}, false
+	}
+	msg.Deliveries = 1
+	return msg, true
+}
+
+// Complete 确认消息处理完成
+// 消息只对当前消费组确认，其他消费组仍可读取；流的长度由 MaxLength 裁剪，只有一个消费组时可以配置 DeleteOnComplete
+func (q *StreamQueue[T]) Complete(msg StreamMessage[T]) error {
+	pipe := q.redis.Pipeline()
+	q.sendAck(pipe, msg.ID)
+	return pipe.Exec()
+}
+
+// Fail 消息处理失败
+// 消息保留在待处理列表中，超过 ClaimIdle 后重新投递；
+// ErrorHandler 返回 false 时确认并丢弃消息，投递次数达到 MaxRetry 时确认并移入死信流
+func (q *StreamQueue[T]) Fail(msg StreamMessage[T], err error) error {
+	if q.config.ErrorHandler != nil && !q.config.ErrorHandler(msg.Value, err) {
+		return q.Complete(msg)
+	}
+	if q.config.MaxRetry > 0 && msg.Deliveries >= q.config.MaxRetry {
+		return q.bury(msg)
+	}
+	return nil
+}
+
+// DeadLetters 获取死信流中最早的 count 条消息，无法解析的消息不返回
+func (q *StreamQueue[T]) DeadLetters(count int) ([]StreamMessage[T], error) {
+	entries, err := redis.Values(q.redis.Do("XRANGE", q.deadName, "-", "+", "COUNT", count))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]StreamMessage[T], 0, len(entries))
+	for _, entry := range entries {
+		if msg, ok := q.parseEntry(entry); ok {
+			result = append(result, msg)
+		}
+	}
+	return result, nil
+}
+
+// DeadLength 获取死信流中的消息数量
+func (q *StreamQueue[T]) DeadLength() int {
+	length, err := redis.Int(q.redis.Do("XLEN", q.deadName))
+	if err != nil {
+		return 0
+	}
+	return length
+}
+
+// Claim 认领空闲时间超过 minIdle 的待处理消息，最多 count 条
+func (q *StreamQueue[T]) Claim(minIdle time.Duration, count int) ([]StreamMessage[T], error) {
+	if err := q.ensureGroup(); err != nil {
+		return nil, err
+	}
+
+	reply, err := redis.Values(q.redis.Do("XAUTOCLAIM", q.name, q.config.Group, q.config.Consumer,
+		minIdle.Milliseconds(), "0-0", "COUNT", count))
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) < 2 {
+		return nil, nil
+	}
+
+	entries, err := redis.Values(reply[1], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]StreamMessage[T], 0, len(entries))
+	for _, entry := range entries {
+		msg, ok := q.parseEntry(entry)
+		if !ok {
+			q.discardEntry(entry)
+			continue
+		}
+		msg.Deliveries = q.deliveries(msg.ID)
+		result = append(result, msg)
+	}
+	return result, nil
+}
+
+// Pending 获取待处理（已投递未确认）的消息信息，最多 count 条
+func (q *StreamQueue[T]) Pending(count int) ([]StreamPendingEntry, error) {
+	if err := q.ensureGroup(); err != nil {
+		return nil, err
+	}
+
+	values, err := redis.Values(q.redis.Do("XPENDING", q.name, q.config.Group, "-", "+", count))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]StreamPendingEntry, 0, len(values))
+	for _, value := range values {
+		fields, err := redis.Values(value, nil)
+		if err != nil || len(fields) < 4 {
+			continue
+		}
+		id, _ := redis.String(fields[0], nil)
+		consumer, _ := redis.String(fields[1], nil)
+		idle, _ := redis.Int64(fields[2], nil)
+		deliveries, _ := redis.Int(fields[3], nil)
+		result = append(result, StreamPendingEntry{
+			ID:         id,
+			Consumer:   consumer,
+			Idle:       time.Duration(idle) * time.Millisecond,
+			Deliveries: deliveries,
+		})
+	}
+	return result, nil
+}
+
+// Length 获取流中的消息数量（包括待处理的消息）
+func (q *StreamQueue[T]) Length() int {
+	length, err := redis.Int(q.redis.Do("XLEN", q.name))
+	if err != nil {
+		return 0
+	}
+	return length
+}
+
+// PendingLength 获取待处理消息数量
+func (q *StreamQueue[T]) PendingLength() int {
+	if err := q.ensureGroup(); err != nil {
+		return 0
+	}
+
+	values, err := redis.Values(q.redis.Do("XPENDING", q.name, q.config.Group))
+	if err != nil || len(values) == 0 {
+		return 0
+	}
+	length, _ := redis.Int(values[0], nil)
+	return length
+}
+
+// Clear 清空队列，同时删除消费组和死信流
+func (q *StreamQueue[T]) Clear() error {
+	_, err := q.redis.Do("DEL", q.name, q.deadName)
+	q.groupReady.Store(false)
+	return err
+}
+
+// StartWorker 启动工作线程，返回的线程池可用于调整并发数、查看统计信息和停止
+func (q *StreamQueue[T]) StartWorker(handler func(value T) error) *WorkerPool[T] {
+	return q.StartWorkers(1, handler)
+}
+
+// StartWorkers 启动多个工作线程，返回的线程池可用于调整并发数、查看统计信息和停止
+func (q *StreamQueue[T]) StartWorkers(count int, handler func(value T) error) *WorkerPool[T] {
+	return newWorkerPool(q.takeTask, q.config.IdleBackoff, q.config.MaxIdleBackoff, count, handler)
+}
+
+// takeTask 取出消息供工作线程池处理，处理成功时 Complete，失败时 Fail
+func (q *StreamQueue[T]) takeTask() (T, func(err error), bool) {
+	msg, ok := q.Take()
+	if !ok {
+		return msg.Value, nil, false
+	}
+	return msg.Value, func(err error) {
+		if err != nil {
+			q.Fail(msg, err)
+		} else {
+			q.Complete(msg)
+		}
+	}, true
+}
+
+// claimOne 认领一条超时未确认的消息，超过 MaxRetry 的消息移入死信流
+func (q *StreamQueue[T]) claimOne() (StreamMessage[T], bool) {
+	for {
+		messages, err := q.Claim(q.config.ClaimIdle, 1)
+		if err != nil || len(messages) == 0 {
+			return StreamMessage[T]{}, false
+		}
+
+		msg := messages[0]
+		if q.config.MaxRetry > 0 && msg.Deliveries > q.config.MaxRetry {
+			q.bury(msg)
+			continue
+		}
+		return msg, true
+	}
+}
+
+// deliveries 查询消息的投递次数
+func (q *StreamQueue[T]) deliveries(id string) int {
+	values, err := redis.Values(q.redis.Do("XPENDING", q.name, q.config.Group, id, id, 1))
+	if err != nil || len(values) == 0 {
+		return 0
+	}
+	fields, err := redis.Values(values[0], nil)
+	if err != nil || len(fields) < 4 {
+		return 0
+	}
+	deliveries, _ := redis.Int(fields[3], nil)
+	return deliveries
+}
+
+// readGroup 执行 XREADGROUP，消费组被删除时重新创建后重试一次
+func (q *StreamQueue[T]) readGroup(args []interface{}) (interface{}, error) {
+	reply, err := q.redis.Do("XREADGROUP", args...)
+	if err != nil && strings.HasPrefix(err.Error(), "NOGROUP") {
+		q.groupReady.Store(false)
+		if err := q.ensureGroup(); err != nil {
+			return nil, err
+		}
+		reply, err = q.redis.Do("XREADGROUP", args...)
+	}
+	return reply, err
+}
+
+// ensureGroup 确保消费组存在，流不存在时一并创建
+func (q *StreamQueue[T]) ensureGroup() error {
+	if q.groupReady.Load() {
+		return nil
+	}
+
+	_, err := q.redis.Do("XGROUP", "CREATE", q.name, q.config.Group, "0", "MKSTREAM")
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("create consumer group: %w", err)
+	}
+	q.groupReady.Store(true)
+	return nil
+}
+
+// parseEntry 解析流消息 [id, [field, value, ...]]
+func (q *StreamQueue[T]) parseEntry(entry interface{}) (StreamMessage[T], bool) {
+	var msg StreamMessage[T]
+
+	values, err := redis.Values(entry, nil)
+	if err != nil || len(values) != 2 {
+		return msg, false
+	}
+	msg.ID, err = redis.String(values[0], nil)
+	if err != nil {
+		return msg, false
+	}
+
+	fields, err := redis.ByteSlices(values[1], nil)
+	if err != nil {
+		return msg, false
+	}
+	for i := 0; i+1 < len(fields); i += 2 {
+		if string(fields[i]) != streamDataField {
+			continue
+		}
+
+		var zero T
+		result := reflect.New(reflect.TypeOf(zero)).Interface()
+		if err := q.redis.Deserialize(fields[i+1], result); err != nil {
+			return msg, false
+		}
+		msg.Value = reflect.ValueOf(result).Elem().Interface().(T)
+		return msg, true
+	}
+	return msg, false
+}
+
+// bury 把消息移入死信流并确认
+func (q *StreamQueue[T]) bury(msg StreamMessage[T]) error {
+	data, err := q.redis.Serialize(msg.Value)
+	if err != nil {
+		return err
+	}
+
+	pipe := q.redis.Pipeline()
+	pipe.Send("XADD", q.deadName, "*", streamDataField, data)
+	q.sendAck(pipe, msg.ID)
+	return pipe.Exec()
+}
+
+// discardEntry 把无法解析的消息原样移入死信流并确认，避免它一直留在待处理列表中被反复投递
+func (q *StreamQueue[T]) discardEntry(entry interface{}) {
+	values, err := redis.Values(entry, nil)
+	if err != nil || len(values) != 2 {
+		return
+	}
+	id, err := redis.String(values[0], nil)
+	if err != nil {
+		return
+	}
+
+	pipe := q.redis.Pipeline()
+	// 已被删除的消息认领时没有字段，只需确认
+	if fields, err := redis.Values(values[1], nil); err == nil && len(fields) > 0 {
+		pipe.Send("XADD", append([]interface{}{q.deadName, "*"}, fields...)...)
+	}
+	q.sendAck(pipe, id)
+	pipe.Exec()
+}
+
+// sendAck 在管道中确认消息，配置了 DeleteOnComplete 时同时从流中删除
+func (q *StreamQueue[T]) sendAck(pipe *Pipeline, id string) {
+	pipe.Send("XACK", q.name, q.config.Group, id)
+	if q.config.DeleteOnComplete {
+		pipe.Send("XDEL", q.name, id)
+	}
+}
+
+// newStreamQueueConfig 填充流队列配置的默认值
+func newStreamQueueConfig(config StreamQueueConfig) StreamQueueConfig {
+	if config.Group == "" {
+		config.Group = "default"
+	}
+	if config.Consumer == "" {
+		config.Consumer = uuid.New().String()
+	}
+	if config.ClaimIdle == 0 {
+		config.ClaimIdle = time.Second * 30
+	}
+	return config
+}
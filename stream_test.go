@@ -0,0 +1,346 @@
+package redisTool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+func TestStreamQueue_AddTakeComplete(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewStreamQueue[TestStruct]("teststream", StreamQueueConfig{}, tr.Redis)
+
+	if err := queue.Add(TestStruct{Name: "Alice", Age: 30}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	queue.Add(TestStruct{Name: "Bob", Age: 25})
+
+	if queue.Length() != 2 {
+		t.Errorf("Length() = %v, want 2", queue.Length())
+	}
+
+	msg, ok := queue.Take()
+	if !ok {
+		t.Fatal("Take() returned false")
+	}
+	if msg.Value.Name != "Alice" || msg.Deliveries != 1 || msg.ID == "" {
+		t.Errorf("Take() = %+v", msg)
+	}
+
+	if queue.PendingLength() != 1 {
+		t.Errorf("PendingLength() = %v, want 1", queue.PendingLength())
+	}
+
+	if err := queue.Complete(msg); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if queue.PendingLength() != 0 {
+		t.Errorf("PendingLength() after Complete = %v, want 0", queue.PendingLength())
+	}
+	// 确认后消息仍保留在流中，供其他消费组读取
+	if queue.Length() != 2 {
+		t.Errorf("Length() after Complete = %v, want 2", queue.Length())
+	}
+}
+
+func TestStreamQueue_MultipleGroups(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	groupA := NewStreamQueue[string]("teststream", StreamQueueConfig{Group: "a"}, tr.Redis)
+	groupB := NewStreamQueue[string]("teststream", StreamQueueConfig{Group: "b"}, tr.Redis)
+	groupA.Take()
+	groupB.Take()
+
+	groupA.Add("task1")
+
+	msg, ok := groupA.Take()
+	if !ok {
+		t.Fatal("Take() on group a returned false")
+	}
+	if err := groupA.Complete(msg); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	if msg, ok := groupB.Take(); !ok || msg.Value != "task1" {
+		t.Errorf("Take() on group b = %+v, %v, want task1", msg, ok)
+	}
+}
+
+func TestStreamQueue_DeleteOnComplete(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewStreamQueue[string]("teststream", StreamQueueConfig{DeleteOnComplete: true}, tr.Redis)
+	queue.Add("task1")
+
+	msg, _ := queue.Take()
+	if err := queue.Complete(msg); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if queue.Length() != 0 {
+		t.Errorf("Length() after Complete = %v, want 0", queue.Length())
+	}
+}
+
+func TestStreamQueue_TakeEmpty(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewStreamQueue[string]("teststream", StreamQueueConfig{}, tr.Redis)
+
+	if _, ok := queue.Take(); ok {
+		t.Error("Take() on empty queue should return false")
+	}
+}
+
+func TestStreamQueue_ClaimStuckMessage(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	crashed := NewStreamQueue[string]("teststream", StreamQueueConfig{
+		Consumer:  "crashed",
+		ClaimIdle: time.Millisecond * 50,
+	}, tr.Redis)
+	worker := NewStreamQueue[string]("teststream", StreamQueueConfig{
+		Consumer:  "worker",
+		ClaimIdle: time.Millisecond * 50,
+	}, tr.Redis)
+
+	crashed.Add("task1")
+
+	// 第一个消费者取走消息后崩溃，没有确认
+	if _, ok := crashed.Take(); !ok {
+		t.Fatal("Take() returned false")
+	}
+
+	pending, err := worker.Pending(10)
+	if err != nil {
+		t.Fatalf("Pending() error = %v", err)
+	}
+	if len(pending) != 1 || pending[0].Consumer != "crashed" {
+		t.Fatalf("Pending() = %+v", pending)
+	}
+
+	time.Sleep(time.Millisecond * 100)
+
+	msg, ok := worker.Take()
+	if !ok {
+		t.Fatal("Take() should claim the stuck message")
+	}
+	if msg.Value != "task1" || msg.Deliveries != 2 {
+		t.Errorf("claimed message = %+v, want task1 with 2 deliveries", msg)
+	}
+
+	pending, _ = worker.Pending(10)
+	if len(pending) != 1 || pending[0].Consumer != "worker" {
+		t.Errorf("Pending() after claim = %+v", pending)
+	}
+}
+
+func TestStreamQueue_FailMaxRetry(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewStreamQueue[string]("teststream", StreamQueueConfig{
+		ClaimIdle: time.Millisecond * 10,
+		MaxRetry:  2,
+	}, tr.Redis)
+
+	queue.Add("task1")
+
+	msg, _ := queue.Take()
+	queue.Fail(msg, errors.New("failed"))
+	if queue.PendingLength() != 1 {
+		t.Fatalf("PendingLength() after first Fail = %v, want 1", queue.PendingLength())
+	}
+
+	time.Sleep(time.Millisecond * 20)
+
+	msg, ok := queue.Take()
+	if !ok || msg.Deliveries != 2 {
+		t.Fatalf("Take() retry = %+v, %v", msg, ok)
+	}
+	queue.Fail(msg, errors.New("failed again"))
+
+	// 达到最大投递次数后移入死信流
+	if queue.PendingLength() != 0 {
+		t.Errorf("PendingLength() after MaxRetry = %v, want 0", queue.PendingLength())
+	}
+	dead, err := queue.DeadLetters(10)
+	if err != nil || len(dead) != 1 || dead[0].Value != "task1" {
+		t.Errorf("DeadLetters() = %+v, %v, want task1", dead, err)
+	}
+}
+
+func TestStreamQueue_ClaimExhaustedToDead(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewStreamQueue[string]("teststream", StreamQueueConfig{
+		ClaimIdle: time.Millisecond * 10,
+		MaxRetry:  1,
+	}, tr.Redis)
+
+	queue.Add("poison")
+
+	// 消费者处理时崩溃，不确认
+	if _, ok := queue.Take(); !ok {
+		t.Fatal("Take() returned false")
+	}
+	time.Sleep(time.Millisecond * 20)
+
+	if msg, ok := queue.Take(); ok {
+		t.Errorf("Take() after MaxRetry = %+v, want false", msg)
+	}
+	if queue.PendingLength() != 0 {
+		t.Errorf("PendingLength() = %v, want 0", queue.PendingLength())
+	}
+	if queue.DeadLength() != 1 {
+		t.Errorf("DeadLength() = %v, want 1", queue.DeadLength())
+	}
+}
+
+func TestStreamQueue_ErrorHandlerGiveUp(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewStreamQueue[string]("teststream", StreamQueueConfig{
+		ErrorHandler: func(value interface{}, err error) bool {
+			return false
+		},
+	}, tr.Redis)
+
+	queue.Add("task1")
+	msg, _ := queue.Take()
+	queue.Fail(msg, errors.New("fatal"))
+
+	if queue.PendingLength() != 0 {
+		t.Errorf("PendingLength() = %v, want 0", queue.PendingLength())
+	}
+}
+
+func TestStreamQueue_MaxLength(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewStreamQueue[int]("teststream", StreamQueueConfig{MaxLength: 5}, tr.Redis)
+
+	// MAXLEN ~ 为近似裁剪，这里只验证带裁剪参数的 XADD 可以正常执行
+	for i := 0; i < 20; i++ {
+		if err := queue.Add(i); err != nil {
+			t.Fatalf("Add() with MaxLength error = %v", err)
+		}
+	}
+
+	if queue.Length() == 0 || queue.Length() > 20 {
+		t.Errorf("Length() = %v, want 1..20", queue.Length())
+	}
+}
+
+func TestStreamQueue_Clear(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewStreamQueue[string]("teststream", StreamQueueConfig{}, tr.Redis)
+	queue.Add("task1")
+	queue.Take()
+
+	if err := queue.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if queue.Length() != 0 {
+		t.Errorf("Length() after Clear = %v, want 0", queue.Length())
+	}
+
+	// 清空后仍可继续使用
+	queue.Add("task2")
+	msg, ok := queue.Take()
+	if !ok || msg.Value != "task2" {
+		t.Errorf("Take() after Clear = %+v, %v", msg, ok)
+	}
+}
+
+func TestStreamQueue_UndecodableMessage(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewStreamQueue[int]("teststream", StreamQueueConfig{}, tr.Redis)
+
+	// 其他程序写入的消息缺少数据字段，无法解析
+	if _, err := tr.Redis.Do("XADD", queue.Name(), "*", "other", "x"); err != nil {
+		t.Fatalf("XADD error = %v", err)
+	}
+	queue.Add(1)
+
+	if _, ok := queue.Take(); ok {
+		t.Error("Take() of an undecodable message should return false")
+	}
+	if queue.PendingLength() != 0 {
+		t.Errorf("PendingLength() = %v, want 0", queue.PendingLength())
+	}
+	if dead, _ := redis.Int(tr.Redis.Do("XLEN", queue.Name()+":dead")); dead != 1 {
+		t.Errorf("dead stream length = %v, want 1", dead)
+	}
+
+	msg, ok := queue.Take()
+	if !ok || msg.Value != 1 {
+		t.Errorf("Take() = %+v, %v, want 1", msg, ok)
+	}
+}
+
+func TestStreamQueue_Workers(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	failed := make(chan error, 1)
+	queue := NewStreamQueue[string]("teststream", StreamQueueConfig{
+		IdleBackoff: time.Millisecond * 10,
+		ErrorHandler: func(value interface{}, err error) bool {
+			failed <- err
+			return false
+		},
+	}, tr.Redis)
+
+	pool := queue.StartWorkers(2, func(value string) error {
+		if value == "panic" {
+			panic("boom")
+		}
+		return nil
+	})
+
+	queue.Add("ok")
+	queue.Add("panic")
+
+	select {
+	case err := <-failed:
+		if err == nil {
+			t.Error("ErrorHandler got nil error for panic")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic was not routed to Fail")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := pool.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	var processed, failedCount int64
+	for _, s := range pool.Stats() {
+		processed += s.Processed
+		failedCount += s.Failed
+	}
+	if processed != 1 || failedCount != 1 {
+		t.Errorf("Stats() processed = %d, failed = %d, want 1 and 1", processed, failedCount)
+	}
+	if queue.PendingLength() != 0 {
+		t.Errorf("PendingLength() = %v, want 0", queue.PendingLength())
+	}
+}
@@ -0,0 +1,209 @@
+package redisTool
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	topicBufferSize   = 64               // 订阅通道缓冲大小
+	topicPingInterval = time.Second * 30 // 订阅连接心跳间隔
+	topicReadTimeout  = topicPingInterval * 2
+	topicMinReconnect = time.Millisecond * 100 // 重连初始等待时间
+	topicMaxReconnect = time.Second * 10       // 重连最长等待时间
+)
+
+// Topic 类型化的发布订阅主题
+type Topic[T any] struct {
+	redis *Redis
+	name  string
+}
+
+// TopicMessage 模式订阅收到的消息
+type TopicMessage[T any] struct {
+	Channel string // 消息实际发布到的 Redis 频道名
+	Value   T
+}
+
+// Name 获取 Redis 频道名
+func (t *Topic[T]) Name() string {
+	return t.name
+}
+
+// WithContext 返回绑定了上下文的主题视图，只影响 Publish
+func (t *Topic[T]) WithContext(ctx context.Context) *Topic[T] {
+	return &Topic[T]{
+		redis: t.redis.WithContext(ctx),
+		name:  t.name,
+	}
+}
+
+// Publish 发布消息，返回收到消息的订阅者数量
+func (t *Topic[T]) Publish(value T) (int, error) {
+	data, err := t.redis.Serialize(value)
+	if err != nil {
+		return 0, err
+	}
+	return redis.Int(t.redis.Do("PUBLISH", t.name, data))
+}
+
+// Subscribe 订阅主题
+// 订阅使用独立连接，连接断开后自动重连；ctx 取消后关闭连接并关闭返回的通道
+func (t *Topic[T]) Subscribe(ctx context.Context) (<-chan T, error) {
+	ch := make(chan T, topicBufferSize)
+	deliver := func(channel string, value T) bool {
+		select {
+		case ch <- value:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	if err := t.listen(ctx, false, deliver, func() { close(ch) }); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// PSubscribe 将主题名作为通配模式订阅，例如 NewTopic[T]("orders:*")
+// 订阅使用独立连接，连接断开后自动重连；ctx 取消后关闭连接并关闭返回的通道
+func (t *Topic[T]) PSubscribe(ctx context.Context) (<-chan TopicMessage[T], error) {
+	ch := make(chan TopicMessage[T], topicBufferSize)
+	deliver := func(channel string, value T) bool {
+		select {
+		case ch <- TopicMessage[T]{Channel: channel, Value: value}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	if err := t.listen(ctx, true, deliver, func() { close(ch) }); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// listen 建立订阅并在后台接收消息，首次订阅失败时直接返回错误
+func (t *Topic[T]) listen(ctx context.Context, pattern bool, deliver func(channel string, value T) bool, done func()) error {
+	psc, err := t.connect(pattern)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer done()
+
+		for {
+			t.receive(ctx, psc, deliver)
+			psc.Close()
+
+			// 等待后重连，直到成功或 ctx 取消
+			backoff := topicMinReconnect
+			for {
+				if ctx.Err() != nil {
+					return
+				}
+
+				timer := time.NewTimer(backoff)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+
+				if psc, err = t.connect(pattern); err == nil {
+					break
+				}
+
+				backoff *= 2
+				if backoff > topicMaxReconnect {
+					backoff = topicMaxReconnect
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// connect 使用独立连接订阅，并等待订阅确认
+func (t *Topic[T]) connect(pattern bool) (redis.PubSubConn, error) {
+	conn, err := t.redis.pool.Dial()
+	if err != nil {
+		return redis.PubSubConn{}, err
+	}
+
+	psc := redis.PubSubConn{Conn: conn}
+	if pattern {
+		err = psc.PSubscribe(t.name)
+	} else {
+		err = psc.Subscribe(t.name)
+	}
+	if err != nil {
+		conn.Close()
+		return redis.PubSubConn{}, err
+	}
+
+	switch reply := psc.ReceiveWithTimeout(topicReadTimeout).(type) {
+	case redis.Subscription:
+		return psc, nil
+	case error:
+		conn.Close()
+		return redis.PubSubConn{}, reply
+	default:
+		conn.Close()
+		return redis.PubSubConn{}, redis.Error("redisTool: unexpected subscribe reply")
+	}
+}
+
+// receive 接收消息直到连接出错、ctx 取消或 deliver 返回 false
+func (t *Topic[T]) receive(ctx context.Context, psc redis.PubSubConn, deliver func(channel string, value T) bool) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// ctx 取消时关闭连接以中断阻塞的 Receive；定期心跳以发现失效的连接
+	go func() {
+		ticker := time.NewTicker(topicPingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				psc.Close()
+				return
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := psc.Ping(""); err != nil {
+					psc.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	var zero T
+	for {
+		switch msg := psc.ReceiveWithTimeout(topicReadTimeout).(type) {
+		case redis.Message:
+			result := reflect.New(reflect.TypeOf(zero)).Interface()
+			if err := t.redis.Deserialize(msg.Data, result); err != nil {
+				continue
+			}
+			if !deliver(msg.Channel, reflect.ValueOf(result).Elem().Interface().(T)) {
+				return
+			}
+		case redis.Subscription:
+			if msg.Count == 0 {
+				return
+			}
+		case error:
+			return
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package redisTool
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTopic_PublishSubscribe(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	topic := NewTopic[TestStruct]("events", tr.Redis)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := topic.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	receivers, err := topic.Publish(TestStruct{Name: "Alice", Age: 30})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if receivers != 1 {
+		t.Errorf("Publish() receivers = %v, want 1", receivers)
+	}
+
+	select {
+	case value := <-ch:
+		if value.Name != "Alice" {
+			t.Errorf("received Name = %v, want Alice", value.Name)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("timed out waiting for message")
+	}
+}
+
+func TestTopic_PSubscribe(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := NewTopic[string]("orders:*", tr.Redis).PSubscribe(ctx)
+	if err != nil {
+		t.Fatalf("PSubscribe() error = %v", err)
+	}
+
+	created := NewTopic[string]("orders:created", tr.Redis)
+	if _, err := created.Publish("order-1"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Value != "order-1" {
+			t.Errorf("received Value = %v, want order-1", msg.Value)
+		}
+		if msg.Channel != created.Name() {
+			t.Errorf("received Channel = %v, want %v", msg.Channel, created.Name())
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("timed out waiting for message")
+	}
+}
+
+func TestTopic_CancelClosesChannel(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	topic := NewTopic[string]("events", tr.Redis)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := topic.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("channel should be closed without messages after cancel")
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("channel was not closed after cancel")
+	}
+}
+
+func TestTopic_Reconnect(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	if tr.MiniRedis == nil {
+		t.Skip("reconnect test requires miniredis")
+	}
+
+	topic := NewTopic[string]("events", tr.Redis)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := topic.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// 重启服务器，订阅连接被断开
+	tr.MiniRedis.Close()
+	if err := tr.MiniRedis.Restart(); err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+
+	deadline := time.After(time.Second * 5)
+	for {
+		topic.Publish("after-restart")
+		select {
+		case value := <-ch:
+			if value != "after-restart" {
+				t.Errorf("received = %v, want after-restart", value)
+			}
+			return
+		case <-time.After(time.Millisecond * 100):
+		case <-deadline:
+			t.Fatal("subscriber did not reconnect")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package redisTool
+
+import (
+	"errors"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// txMaxRetry 事务因 WATCH 的键被修改而失败时的最大重试次数
+const txMaxRetry = 16
+
+// ErrTxAborted 事务在重试次数用尽后仍因 WATCH 的键被修改而失败
+var ErrTxAborted = errors.New("redisTool: transaction aborted, watched keys kept changing")
+
+// Tx 事务，命令在 MULTI/EXEC 中原子执行
+type Tx struct {
+	conn     redis.Conn
+	commands []pipelineCommand
+	results  []*Result
+}
+
+// Transaction 执行事务（WATCH + MULTI/EXEC 乐观锁）
+// fn 在 WATCH 之后调用，可以通过 tx.Do 或任意数据结构读取数据，再通过 tx.Send 或各数据结构的 SendXxx 方法排队写入命令；
+// 如果 EXEC 时被 WATCH 的键已被修改，会自动重新执行 fn，因此 fn 应当没有副作用；
+// fn 返回错误时放弃事务并返回该错误
+func (r *Redis) Transaction(watchKeys []string, fn func(tx *Tx) error) error {
+	for i := 0; i < txMaxRetry; i++ {
+		committed, err := r.runTransaction(watchKeys, fn)
+		if err != nil || committed {
+			return err
+		}
+	}
+	return ErrTxAborted
+}
+
+// runTransaction 执行一次事务，返回是否提交成功
+func (r *Redis) runTransaction(watchKeys []string, fn func(tx *Tx) error) (bool, error) {
+	conn := r.GetConn()
+	defer conn.Close()
+
+	if len(watchKeys) > 0 {
+		args := make([]interface{}, len(watchKeys))
+		for i, key := range watchKeys {
+			args[i] = key
+		}
+		if _, err := conn.Do("WATCH", args...); err != nil {
+			return false, err
+		}
+	}
+
+	tx := &Tx{conn: conn}
+	if err := fn(tx); err != nil {
+		conn.Do("UNWATCH")
+		return false, err
+	}
+
+	if len(tx.commands) == 0 {
+		_, err := conn.Do("UNWATCH")
+		return true, err
+	}
+
+	if err := conn.Send("MULTI"); err != nil {
+		return false, err
+	}
+	for _, cmd := range tx.commands {
+		if err := conn.Send(cmd.name, cmd.args...); err != nil {
+			return false, err
+		}
+	}
+
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err == redis.ErrNil {
+		// 被 WATCH 的键已被修改，需要重试
+		return false, nil
+	}
+	if err != nil {
+		failResults(tx.results, err)
+		return false, err
+	}
+
+	var firstErr error
+	for i, result := range tx.results {
+		if i >= len(replies) {
+			break
+		}
+		result.reply, result.err = replies[i], nil
+		if e, ok := replies[i].(redis.Error); ok {
+			result.reply, result.err = nil, e
+			if firstErr == nil {
+				firstErr = e
+			}
+		}
+	}
+	return true, firstErr
+}
+
+// Do 在事务连接上立即执行命令，用于在 MULTI 之前读取数据
+func (tx *Tx) Do(commandName string, args ...interface{}) (interface{}, error) {
+	return tx.conn.Do(commandName, args...)
+}
+
+// Send 将命令加入事务，返回的结果在 EXEC 之后可用
+func (tx *Tx) Send(commandName string, args ...interface{}) *Result {
+	result := &Result{err: ErrNotExecuted}
+	tx.commands = append(tx.commands, pipelineCommand{name: commandName, args: args})
+	tx.results = append(tx.results, result)
+	return result
+}
+
+// Len 获取排队中的命令数量
+func (tx *Tx) Len() int {
+	return len(tx.commands)
+}
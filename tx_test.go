@@ -0,0 +1,137 @@
+package redisTool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTransaction_Commit(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	accounts := NewTypeMap[int]("accounts", tr.Redis)
+	history := NewTypeList[string]("history", tr.Redis)
+	accounts.Set("alice", 100)
+	accounts.Set("bob", 50)
+
+	err := tr.Redis.Transaction([]string{accounts.Name()}, func(tx *Tx) error {
+		alice, _ := accounts.Get("alice")
+		bob, _ := accounts.Get("bob")
+
+		if _, err := accounts.SendSet(tx, "alice", alice-30); err != nil {
+			return err
+		}
+		if _, err := accounts.SendSet(tx, "bob", bob+30); err != nil {
+			return err
+		}
+		_, err := history.SendPush(tx, "alice->bob:30")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Transaction() error = %v", err)
+	}
+
+	if v, _ := accounts.Get("alice"); v != 70 {
+		t.Errorf("alice = %v, want 70", v)
+	}
+	if v, _ := accounts.Get("bob"); v != 80 {
+		t.Errorf("bob = %v, want 80", v)
+	}
+	if history.Length() != 1 {
+		t.Errorf("history Length() = %v, want 1", history.Length())
+	}
+}
+
+func TestTransaction_RetryOnConflict(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	counter := NewTypeMap[int]("counter", tr.Redis)
+	counter.Set("value", 1)
+
+	attempts := 0
+	err := tr.Redis.Transaction([]string{counter.Name()}, func(tx *Tx) error {
+		attempts++
+		value, _ := counter.Get("value")
+
+		// 第一次执行时模拟其他客户端修改了被 WATCH 的键
+		if attempts == 1 {
+			counter.Set("value", 10)
+		}
+
+		_, err := counter.SendSet(tx, "value", value*2)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Transaction() error = %v", err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("attempts = %v, want 2", attempts)
+	}
+	if v, _ := counter.Get("value"); v != 20 {
+		t.Errorf("value = %v, want 20", v)
+	}
+}
+
+func TestTransaction_Aborted(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	counter := NewTypeMap[int]("counter", tr.Redis)
+	counter.Set("value", 1)
+
+	err := tr.Redis.Transaction([]string{counter.Name()}, func(tx *Tx) error {
+		counter.Set("value", 2)
+		_, err := counter.SendSet(tx, "value", 3)
+		return err
+	})
+	if err != ErrTxAborted {
+		t.Errorf("Transaction() error = %v, want ErrTxAborted", err)
+	}
+	if v, _ := counter.Get("value"); v != 2 {
+		t.Errorf("value = %v, want 2", v)
+	}
+}
+
+func TestTransaction_FnError(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	counter := NewTypeMap[int]("counter", tr.Redis)
+	wantErr := errors.New("insufficient balance")
+
+	err := tr.Redis.Transaction([]string{counter.Name()}, func(tx *Tx) error {
+		counter.SendSet(tx, "value", 100)
+		return wantErr
+	})
+	if err != wantErr {
+		t.Errorf("Transaction() error = %v, want %v", err, wantErr)
+	}
+	if counter.Exists("value") {
+		t.Error("queued commands should be discarded when fn returns error")
+	}
+}
+
+func TestTransaction_Results(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	scores := tr.Redis.NewNumberMap("scores")
+
+	var incr *Result
+	err := tr.Redis.Transaction(nil, func(tx *Tx) error {
+		if _, err := tx.Do("HSET", scores.Name(), "alice", 10); err != nil {
+			return err
+		}
+		incr = scores.SendIncrement(tx, "alice", 5)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction() error = %v", err)
+	}
+
+	if v, err := incr.Float64(); err != nil || v != 15 {
+		t.Errorf("SendIncrement() result = %v, %v, want 15", v, err)
+	}
+}
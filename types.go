@@ -15,6 +15,8 @@ const (
 	RedisTypeCache_
 	RedisTypeLock_
 	RedisTypeSafeTypeMap_
+	RedisTypeTopic_
+	RedisTypeStream_
 )
 
 // String 返回 RedisType 的字符串表示
@@ -38,6 +40,10 @@ func (rt RedisType) String() string {
 		return "lock"
 	case RedisTypeSafeTypeMap_:
 		return "safetypemap"
+	case RedisTypeTopic_:
+		return "topic"
+	case RedisTypeStream_:
+		return "stream"
 	default:
 		return "unknown"
 	}
@@ -63,15 +69,44 @@ type ZSetItem[T any] struct {
 
 // QueueConfig 队列配置
 type QueueConfig struct {
-	MaxLength    int                                                                           // 队列最大长度，0 表示不限制
-	MaxWaitTime  time.Duration                                                                 // 队列阻塞等待时间，0 表示不阻塞
-	MaxRetry     int                                                                           // 队列重试次数，0 表示不重试
-	ErrorHandler func(value interface{}, err error, storage func(value interface{})) time.Duration // 错误处理器
+	MaxLength         int                                                                               // 队列最大长度，0 表示不限制
+	MaxWaitTime       time.Duration                                                                     // 队列阻塞等待时间，0 表示不阻塞
+	MaxRetry          int                                                                               // 队列重试次数，0 表示不重试，失败或处理超时后直接进入死信队列
+	VisibilityTimeout time.Duration                                                                     // 任务处理超时时间，超时未完成的任务会被放回队列，0 表示不超时
+	ErrorHandler      func(value interface{}, err error, storage func(value interface{})) time.Duration // 错误处理器
+	IdleBackoff       time.Duration                                                                     // 工作线程取不到任务时的等待时间，默认 1 秒
+	MaxIdleBackoff    time.Duration                                                                     // 连续空闲时等待时间逐次翻倍的上限，默认等于 IdleBackoff
+}
+
+// StreamQueueConfig 流队列配置
+type StreamQueueConfig struct {
+	Group            string                                  // 消费组名称，默认 "default"
+	Consumer         string                                  // 消费者名称，默认随机生成
+	MaxLength        int64                                   // 流的最大长度（近似裁剪），0 表示不限制
+	MaxWaitTime      time.Duration                           // Take 阻塞等待时间，0 表示不阻塞
+	ClaimIdle        time.Duration                           // 消息超过该时间未确认时可被认领并重新投递，默认 30 秒
+	MaxRetry         int                                     // 最大投递次数，0 表示不限制
+	ErrorHandler     func(value interface{}, err error) bool // 错误处理器，返回 false 表示不再重试
+	DeleteOnComplete bool                                    // Complete 时是否同时从流中删除消息，只有一个消费组时使用
+	IdleBackoff      time.Duration                           // 工作线程取不到消息时的等待时间，默认 1 秒
+	MaxIdleBackoff   time.Duration                           // 连续空闲时等待时间逐次翻倍的上限，默认等于 IdleBackoff
 }
 
 // CacheConfig 缓存配置
 type CacheConfig struct {
-	DefaultExpire time.Duration // 默认过期时间，0 表示不过期
+	DefaultExpire      time.Duration       // 默认过期时间，0 表示不过期
+	Storage            CacheStorage        // 存储方式，默认 CacheStorageHash
+	LoadLockTime       time.Duration       // GetOrSet 跨进程加载锁的持有时间，默认 10 秒，小于 0 表示只在进程内去重
+	LoadWaitTime       time.Duration       // GetOrSet 未获得加载锁时等待其他进程写入的最长时间，默认 5 秒，超时后自行加载
+	StaleTime          time.Duration       // 过期可用期，条目过期后在这段时间内仍返回旧值并触发后台刷新，0 表示不启用
+	EarlyRefreshBeta   float64             // XFetch 提前刷新系数，越大越早刷新，通常取 1，0 表示不启用
+	LocalSize          int                 // 本地缓存最大条目数，0 表示不启用本地缓存
+	LocalExpire        time.Duration       // 本地缓存条目的最长存活时间，默认 1 分钟，也是错过失效通知时数据不一致的上限
+	NegativeExpire     time.Duration       // GetOrLoad 缓存“不存在”（ErrNotFound）的时间，0 表示不缓存
+	MaxEntries         int                 // 最大条目数，超出时按 EvictionPolicy 淘汰，0 表示不限制
+	EvictionPolicy     CacheEvictionPolicy // 淘汰策略，默认 CacheEvictLRU
+	SharedStats        bool                // 是否把统计信息汇总到 Redis，供 GlobalStats 读取
+	StatsFlushInterval time.Duration       // SharedStats 时统计增量写入 Redis 的间隔，默认 1 秒
 }
 
 // LockConfig 锁配置
@@ -79,4 +114,5 @@ type LockConfig struct {
 	WaitTime           time.Duration // 最长的锁时间，获得锁后，如果在这个时间内没有释放锁，视为出错，自动释放锁
 	RetryTime          time.Duration // 尝试获取锁的间隔时间
 	MaxGetLockWaitTime time.Duration // 获取锁最长等待时间
+	DisableFencing     bool          // 不分配防护令牌，默认每个锁名会在 Redis 中保留一个不过期的令牌计数器
 }
@@ -0,0 +1,234 @@
+package redisTool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ErrNotNumeric 值类型不是数字，无法自增
+var ErrNotNumeric = errors.New("redisTool: value type is not numeric")
+
+// RedisValue 类型化的 Redis 字符串键
+type RedisValue[T any] struct {
+	redis *Redis
+	name  string
+}
+
+// Name 获取 Redis 键名
+func (v *RedisValue[T]) Name() string {
+	return v.name
+}
+
+// WithContext 返回绑定了上下文的视图
+func (v *RedisValue[T]) WithContext(ctx context.Context) *RedisValue[T] {
+	return &RedisValue[T]{
+		redis: v.redis.WithContext(ctx),
+		name:  v.name,
+	}
+}
+
+// Get 获取值
+func (v *RedisValue[T]) Get() (T, bool) {
+	data, err := redis.Bytes(v.redis.Do("GET", v.name))
+	if err != nil {
+		var zero T
+		return zero, false
+	}
+	return v.decode(data)
+}
+
+// Set 设置值，ttl 为 0 表示不过期
+func (v *RedisValue[T]) Set(value T, ttl time.Duration) error {
+	data, err := v.redis.Serialize(value)
+	if err != nil {
+		return err
+	}
+
+	if ttl > 0 {
+		_, err = v.redis.Do("SET", v.name, data, "PX", ttl.Milliseconds())
+	} else {
+		_, err = v.redis.Do("SET", v.name, data)
+	}
+	return err
+}
+
+// SetNX 仅当键不存在时设置值，返回是否设置成功
+func (v *RedisValue[T]) SetNX(value T, ttl time.Duration) (bool, error) {
+	data, err := v.redis.Serialize(value)
+	if err != nil {
+		return false, err
+	}
+
+	var reply interface{}
+	if ttl > 0 {
+		reply, err = v.redis.Do("SET", v.name, data, "NX", "PX", ttl.Milliseconds())
+	} else {
+		reply, err = v.redis.Do("SET", v.name, data, "NX")
+	}
+	if err != nil {
+		return false, err
+	}
+	return reply != nil, nil
+}
+
+// GetSet 设置新值并返回旧值，旧值不存在时 exist 为 false
+// 与 Redis GETSET 一致，设置后键的过期时间会被清除
+func (v *RedisValue[T]) GetSet(value T) (old T, exist bool, err error) {
+	data, err := v.redis.Serialize(value)
+	if err != nil {
+		return old, false, err
+	}
+
+	oldData, err := redis.Bytes(v.redis.Do("GETSET", v.name, data))
+	if err == redis.ErrNil {
+		return old, false, nil
+	}
+	if err != nil {
+		return old, false, err
+	}
+
+	old, exist = v.decode(oldData)
+	return old, exist, nil
+}
+
+// CompareAndSwap 当前值等于 oldValue 时设置为 newValue，保留原有的过期时间，返回是否替换成功
+func (v *RedisValue[T]) CompareAndSwap(oldValue, newValue T) (bool, error) {
+	script := `
+		if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+			return 0
+		end
+		local ttl = redis.call('PTTL', KEYS[1])
+		if ttl > 0 then
+			redis.call('SET', KEYS[1], ARGV[2], 'PX', ttl)
+		else
+			redis.call('SET', KEYS[1], ARGV[2])
+		end
+		return 1
+	`
+
+	oldData, err := v.redis.Serialize(oldValue)
+	if err != nil {
+		return false, err
+	}
+	newData, err := v.redis.Serialize(newValue)
+	if err != nil {
+		return false, err
+	}
+
+	conn := v.redis.GetConn()
+	defer conn.Close()
+
+	luaScript := redis.NewScript(1, script)
+	swapped, err := redis.Int(luaScript.Do(conn, v.name, oldData, newData))
+	if err != nil {
+		return false, err
+	}
+	return swapped == 1, nil
+}
+
+// Incr 值加 1，仅适用于数字类型
+func (v *RedisValue[T]) Incr() (T, error) {
+	return v.incr(1)
+}
+
+// IncrBy 值增加 delta，仅适用于数字类型；整数使用 INCRBY，浮点数使用 INCRBYFLOAT
+func (v *RedisValue[T]) IncrBy(delta T) (T, error) {
+	return v.incr(delta)
+}
+
+// Delete 删除键
+func (v *RedisValue[T]) Delete() error {
+	_, err := v.redis.Do("DEL", v.name)
+	return err
+}
+
+// Exists 判断键是否存在
+func (v *RedisValue[T]) Exists() bool {
+	exists, err := redis.Int(v.redis.Do("EXISTS", v.name))
+	if err != nil {
+		return false
+	}
+	return exists == 1
+}
+
+// TTL 获取剩余生存时间，键不存在或没有设置过期时间时返回 false
+func (v *RedisValue[T]) TTL() (time.Duration, bool) {
+	ms, err := redis.Int64(v.redis.Do("PTTL", v.name))
+	if err != nil || ms < 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// Expire 设置生存时间
+func (v *RedisValue[T]) Expire(ttl time.Duration) error {
+	_, err := v.redis.Do("PEXPIRE", v.name, ttl.Milliseconds())
+	return err
+}
+
+// Persist 移除过期时间
+func (v *RedisValue[T]) Persist() error {
+	_, err := v.redis.Do("PERSIST", v.name)
+	return err
+}
+
+// SendSet 将设置值的命令排入管道或事务
+func (v *RedisValue[T]) SendSet(s Sender, value T, ttl time.Duration) (*Result, error) {
+	data, err := v.redis.Serialize(value)
+	if err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		return s.Send("SET", v.name, data, "PX", ttl.Milliseconds()), nil
+	}
+	return s.Send("SET", v.name, data), nil
+}
+
+// incr 按值类型选择自增命令，自增后的结果无法转换为 T 时（例如超出范围）返回反序列化错误
+func (v *RedisValue[T]) incr(delta interface{}) (T, error) {
+	var zero T
+
+	var command string
+	switch reflect.TypeOf(zero).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		command = "INCRBY"
+	case reflect.Float32, reflect.Float64:
+		command = "INCRBYFLOAT"
+	default:
+		return zero, ErrNotNumeric
+	}
+
+	reply, err := v.redis.Do(command, v.name, delta)
+	if err != nil {
+		return zero, err
+	}
+
+	var data []byte
+	if n, ok := reply.(int64); ok {
+		data = []byte(strconv.FormatInt(n, 10))
+	} else if data, err = redis.Bytes(reply, nil); err != nil {
+		return zero, err
+	}
+
+	value := reflect.New(reflect.TypeOf(zero))
+	if err := v.redis.Deserialize(data, value.Interface()); err != nil {
+		return zero, err
+	}
+	return value.Elem().Interface().(T), nil
+}
+
+// decode 反序列化值
+func (v *RedisValue[T]) decode(data []byte) (T, bool) {
+	var zero T
+	result := reflect.New(reflect.TypeOf(zero)).Interface()
+	if err := v.redis.Deserialize(data, result); err != nil {
+		return zero, false
+	}
+	return reflect.ValueOf(result).Elem().Interface().(T), true
+}
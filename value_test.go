@@ -0,0 +1,153 @@
+package redisTool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRedisValue_SetGet(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	value := NewValue[TestStruct]("testvalue", tr.Redis)
+
+	if _, ok := value.Get(); ok {
+		t.Error("Get() on missing key should return false")
+	}
+
+	if err := value.Set(TestStruct{Name: "Alice", Age: 30}, 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	got, ok := value.Get()
+	if !ok || got.Name != "Alice" {
+		t.Errorf("Get() = %v, %v, want Alice", got, ok)
+	}
+
+	if _, ok := value.TTL(); ok {
+		t.Error("TTL() without expiry should return false")
+	}
+
+	if value.Name() != "test:string:testvalue" {
+		t.Errorf("Name() = %v, want test:string:testvalue", value.Name())
+	}
+}
+
+func TestRedisValue_TTL(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	value := NewValue[string]("testvalue", tr.Redis)
+	value.Set("hello", time.Second*10)
+
+	ttl, ok := value.TTL()
+	if !ok || ttl <= 0 || ttl > time.Second*10 {
+		t.Errorf("TTL() = %v, %v", ttl, ok)
+	}
+
+	tr.FastForward(11)
+
+	if value.Exists() {
+		t.Error("Exists() after expiry should return false")
+	}
+}
+
+func TestRedisValue_SetNX(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	value := NewValue[string]("testvalue", tr.Redis)
+
+	ok, err := value.SetNX("first", 0)
+	if err != nil || !ok {
+		t.Fatalf("SetNX() on missing key = %v, %v, want true", ok, err)
+	}
+
+	ok, err = value.SetNX("second", 0)
+	if err != nil || ok {
+		t.Errorf("SetNX() on existing key = %v, %v, want false", ok, err)
+	}
+
+	if got, _ := value.Get(); got != "first" {
+		t.Errorf("Get() = %v, want first", got)
+	}
+}
+
+func TestRedisValue_GetSet(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	value := NewValue[string]("testvalue", tr.Redis)
+
+	_, exist, err := value.GetSet("first")
+	if err != nil || exist {
+		t.Errorf("GetSet() on missing key = %v, %v, want exist=false", exist, err)
+	}
+
+	old, exist, err := value.GetSet("second")
+	if err != nil || !exist || old != "first" {
+		t.Errorf("GetSet() = %v, %v, %v, want first", old, exist, err)
+	}
+}
+
+func TestRedisValue_CompareAndSwap(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	value := NewValue[TestStruct]("testvalue", tr.Redis)
+	alice := TestStruct{Name: "Alice", Age: 30}
+	bob := TestStruct{Name: "Bob", Age: 25}
+	value.Set(alice, time.Minute)
+
+	swapped, err := value.CompareAndSwap(bob, alice)
+	if err != nil || swapped {
+		t.Errorf("CompareAndSwap() with wrong old value = %v, %v, want false", swapped, err)
+	}
+
+	swapped, err = value.CompareAndSwap(alice, bob)
+	if err != nil || !swapped {
+		t.Fatalf("CompareAndSwap() = %v, %v, want true", swapped, err)
+	}
+
+	if got, _ := value.Get(); got.Name != "Bob" {
+		t.Errorf("Get() after swap = %v, want Bob", got.Name)
+	}
+	if _, ok := value.TTL(); !ok {
+		t.Error("CompareAndSwap() should keep the TTL")
+	}
+}
+
+func TestRedisValue_Incr(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	counter := NewValue[int64]("counter", tr.Redis)
+
+	if n, err := counter.Incr(); err != nil || n != 1 {
+		t.Errorf("Incr() = %v, %v, want 1", n, err)
+	}
+	if n, err := counter.IncrBy(10); err != nil || n != 11 {
+		t.Errorf("IncrBy(10) = %v, %v, want 11", n, err)
+	}
+	if n, _ := counter.Get(); n != 11 {
+		t.Errorf("Get() = %v, want 11", n)
+	}
+
+	ratio := NewValue[float64]("ratio", tr.Redis)
+	ratio.Set(1.5, 0)
+	if f, err := ratio.IncrBy(0.25); err != nil || f != 1.75 {
+		t.Errorf("IncrBy(0.25) = %v, %v, want 1.75", f, err)
+	}
+
+	// 自增结果超出 T 的范围时返回反序列化错误
+	small := NewValue[int8]("small", tr.Redis)
+	small.Set(127, 0)
+	if n, err := small.Incr(); err == nil {
+		t.Errorf("Incr() past int8 range = %v, nil, want error", n)
+	}
+
+	name := NewValue[string]("name", tr.Redis)
+	if _, err := name.Incr(); err != ErrNotNumeric {
+		t.Errorf("Incr() on string value error = %v, want ErrNotNumeric", err)
+	}
+}
@@ -0,0 +1,212 @@
+package redisTool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// workerIdleBackoff 工作线程空闲时的默认等待时间
+const workerIdleBackoff = time.Second
+
+// WorkerPool 队列工作线程池
+// 由 Queue 或 StreamQueue 的 StartWorker / StartWorkers 创建，可以在运行时调整并发数、查看统计信息，并通过 Stop 优雅停止
+type WorkerPool[T any] struct {
+	take           workerTakeFunc[T]
+	handler        func(value T) error
+	idleBackoff    time.Duration
+	maxIdleBackoff time.Duration
+
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	workers []*worker
+	nextID  int
+	stopped bool
+}
+
+// WorkerStats 工作线程统计信息
+type WorkerStats struct {
+	ID        int           // 工作线程编号
+	Processed int64         // 处理成功的任务数
+	Failed    int64         // 处理失败的任务数（包括 panic）
+	BusyTime  time.Duration // 执行处理函数的累计时间
+	Busy      bool          // 是否正在处理任务
+}
+
+// workerTakeFunc 取出一个任务，返回任务内容和提交处理结果的函数（err 为 nil 表示处理成功）
+type workerTakeFunc[T any] func() (value T, finish func(err error), ok bool)
+
+// worker 单个工作线程
+type worker struct {
+	id        int
+	stop      chan struct{}
+	processed atomic.Int64
+	failed    atomic.Int64
+	busyTime  atomic.Int64
+	busy      atomic.Bool
+}
+
+// newWorkerPool 创建工作线程池并启动 count 个工作线程
+// idleBackoff 和 maxIdleBackoff 为队列配置中的空闲等待时间，不大于 0 时使用默认值
+func newWorkerPool[T any](take workerTakeFunc[T], idleBackoff, maxIdleBackoff time.Duration, count int,
+	handler func(value T) error) *WorkerPool[T] {
+	p := &WorkerPool[T]{
+		take:           take,
+		handler:        handler,
+		idleBackoff:    idleBackoff,
+		maxIdleBackoff: maxIdleBackoff,
+	}
+	if p.idleBackoff <= 0 {
+		p.idleBackoff = workerIdleBackoff
+	}
+	if p.maxIdleBackoff < p.idleBackoff {
+		p.maxIdleBackoff = p.idleBackoff
+	}
+
+	p.Resize(count)
+	return p
+}
+
+// Size 获取当前工作线程数量
+func (p *WorkerPool[T]) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.workers)
+}
+
+// Resize 调整工作线程数量
+// 减少时被移除的工作线程会处理完当前任务后退出；线程池停止后调用无效
+func (p *WorkerPool[T]) Resize(count int) {
+	if count < 0 {
+		count = 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopped {
+		return
+	}
+
+	for len(p.workers) < count {
+		p.nextID++
+		w := &worker{id: p.nextID, stop: make(chan struct{})}
+		p.workers = append(p.workers, w)
+		p.wg.Add(1)
+		go p.run(w)
+	}
+	for len(p.workers) > count {
+		last := len(p.workers) - 1
+		close(p.workers[last].stop)
+		p.workers = p.workers[:last]
+	}
+}
+
+// Stats 获取每个工作线程的统计信息
+func (p *WorkerPool[T]) Stats() []WorkerStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]WorkerStats, 0, len(p.workers))
+	for _, w := range p.workers {
+		stats = append(stats, WorkerStats{
+			ID:        w.id,
+			Processed: w.processed.Load(),
+			Failed:    w.failed.Load(),
+			BusyTime:  time.Duration(w.busyTime.Load()),
+			Busy:      w.busy.Load(),
+		})
+	}
+	return stats
+}
+
+// Stop 停止所有工作线程，并等待正在处理的任务完成
+// ctx 到期时不再等待并返回 ctx 的错误，未完成的任务仍会在后台处理完
+// 正在阻塞等待任务的线程最多在 MaxWaitTime 后退出
+func (p *WorkerPool[T]) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.stopped {
+		p.stopped = true
+		for _, w := range p.workers {
+			close(w.stop)
+		}
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 工作线程主循环，连续空闲时等待时间翻倍，直到 maxIdleBackoff
+func (p *WorkerPool[T]) run(w *worker) {
+	defer p.wg.Done()
+
+	backoff := p.idleBackoff
+	for {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+
+		value, finish, ok := p.take()
+		if !ok {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-w.stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			backoff *= 2
+			if backoff > p.maxIdleBackoff {
+				backoff = p.maxIdleBackoff
+			}
+			continue
+		}
+
+		backoff = p.idleBackoff
+		p.handle(w, value, finish)
+	}
+}
+
+// handle 执行处理函数，处理函数 panic 时按失败处理
+func (p *WorkerPool[T]) handle(w *worker, value T, finish func(err error)) {
+	w.busy.Store(true)
+	start := time.Now()
+
+	err := p.call(value)
+
+	w.busyTime.Add(int64(time.Since(start)))
+	w.busy.Store(false)
+
+	if err != nil {
+		w.failed.Add(1)
+	} else {
+		w.processed.Add(1)
+	}
+	finish(err)
+}
+
+// call 调用处理函数并把 panic 转换为错误
+func (p *WorkerPool[T]) call(value T) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("redisTool: worker panic: %v", r)
+		}
+	}()
+	return p.handler(value)
+}
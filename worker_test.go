@@ -0,0 +1,201 @@
+package redisTool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool_StopDrainsInFlight(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("workerqueue", QueueConfig{
+		IdleBackoff: time.Millisecond * 10,
+	}, tr.Redis)
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	pool := queue.StartWorker(func(value TestStruct) error {
+		close(started)
+		time.Sleep(time.Millisecond * 200)
+		finished.Store(true)
+		return nil
+	})
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not start processing")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := pool.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if !finished.Load() {
+		t.Error("Stop() returned before in-flight handler finished")
+	}
+
+	stats := pool.Stats()
+	if len(stats) != 1 || stats[0].Processed != 1 {
+		t.Errorf("Stats() = %+v, want one worker with Processed 1", stats)
+	}
+	if stats[0].BusyTime < time.Millisecond*200 {
+		t.Errorf("BusyTime = %v, want >= 200ms", stats[0].BusyTime)
+	}
+
+	// 停止后不再处理新任务
+	queue.Add(TestStruct{Name: "Bob", Age: 25})
+	time.Sleep(time.Millisecond * 50)
+	if queue.Length() != 1 {
+		t.Errorf("Length() after Stop = %v, want 1", queue.Length())
+	}
+}
+
+func TestWorkerPool_StopTimeout(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("workerqueue", QueueConfig{
+		IdleBackoff: time.Millisecond * 10,
+	}, tr.Redis)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool := queue.StartWorker(func(value TestStruct) error {
+		close(started)
+		<-release
+		return nil
+	})
+	defer close(release)
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if err := pool.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestWorkerPool_PanicRoutesToFail(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	failed := make(chan error, 1)
+	queue := NewQueue[TestStruct]("workerqueue", QueueConfig{
+		IdleBackoff: time.Millisecond * 10,
+		ErrorHandler: func(value interface{}, err error, storage func(value interface{})) time.Duration {
+			failed <- err
+			return -1
+		},
+	}, tr.Redis)
+
+	pool := queue.StartWorker(func(value TestStruct) error {
+		panic("boom")
+	})
+	defer pool.Stop(context.Background())
+
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+
+	select {
+	case err := <-failed:
+		if err == nil {
+			t.Error("ErrorHandler got nil error for panic")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic was not routed to Fail")
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	stats := pool.Stats()
+	if len(stats) != 1 || stats[0].Failed != 1 || stats[0].Processed != 0 {
+		t.Errorf("Stats() = %+v, want one worker with Failed 1", stats)
+	}
+}
+
+func TestWorkerPool_Resize(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("workerqueue", QueueConfig{
+		IdleBackoff: time.Millisecond * 10,
+	}, tr.Redis)
+
+	pool := queue.StartWorkers(2, func(value TestStruct) error {
+		return nil
+	})
+	defer pool.Stop(context.Background())
+
+	if pool.Size() != 2 {
+		t.Errorf("Size() = %v, want 2", pool.Size())
+	}
+
+	pool.Resize(4)
+	if pool.Size() != 4 {
+		t.Errorf("Size() after grow = %v, want 4", pool.Size())
+	}
+	ids := make(map[int]bool)
+	for _, s := range pool.Stats() {
+		ids[s.ID] = true
+	}
+	if len(ids) != 4 {
+		t.Errorf("Stats() worker IDs = %v, want 4 distinct", ids)
+	}
+
+	pool.Resize(1)
+	if pool.Size() != 1 {
+		t.Errorf("Size() after shrink = %v, want 1", pool.Size())
+	}
+
+	for i := 0; i < 3; i++ {
+		queue.Add(TestStruct{Name: "User", Age: i})
+	}
+	deadline := time.Now().Add(time.Second)
+	for queue.Length() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if queue.Length() != 0 {
+		t.Errorf("Length() = %v, want 0 after processing", queue.Length())
+	}
+
+	pool.Stop(context.Background())
+	pool.Resize(3)
+	if pool.Size() != 1 {
+		t.Errorf("Size() after Resize on stopped pool = %v, want 1", pool.Size())
+	}
+}
+
+func TestWorkerPool_IdleBackoff(t *testing.T) {
+	tr := NewTestRedis(t)
+	defer tr.Close()
+
+	queue := NewQueue[TestStruct]("workerqueue", QueueConfig{
+		IdleBackoff:    time.Millisecond * 10,
+		MaxIdleBackoff: time.Millisecond * 40,
+	}, tr.Redis)
+
+	processed := make(chan struct{}, 1)
+	pool := queue.StartWorker(func(value TestStruct) error {
+		processed <- struct{}{}
+		return nil
+	})
+	defer pool.Stop(context.Background())
+
+	// 空闲一段时间后，等待时间不超过 MaxIdleBackoff
+	time.Sleep(time.Millisecond * 200)
+	queue.Add(TestStruct{Name: "Alice", Age: 30})
+
+	select {
+	case <-processed:
+	case <-time.After(time.Millisecond * 200):
+		t.Fatal("worker did not pick up task within MaxIdleBackoff")
+	}
+}
@@ -1,6 +1,7 @@
 package redisTool
 
 import (
+	"context"
 	"reflect"
 
 	"github.com/gomodule/redigo/redis"
@@ -25,6 +26,19 @@ func (r *Redis) NewZSet(name string) *RedisZSet {
 	}
 }
 
+// WithContext 返回绑定了上下文的有序集合视图
+func (z *RedisZSet) WithContext(ctx context.Context) *RedisZSet {
+	return &RedisZSet{
+		redis: z.redis.WithContext(ctx),
+		name:  z.name,
+	}
+}
+
+// Name 获取有序集合的 Redis 键名
+func (z *RedisZSet) Name() string {
+	return z.name
+}
+
 // Add 添加元素
 func (z *RedisZSet) Add(value interface{}, score float64) error {
 	data, err := z.redis.Serialize(value)
@@ -172,6 +186,38 @@ func (z *RedisZSet) RemoveRangeByScore(min, max float64) error {
 	return err
 }
 
+// SendAdd 将添加元素的命令排入管道或事务
+func (z *RedisZSet) SendAdd(s Sender, value interface{}, score float64) (*Result, error) {
+	data, err := z.redis.Serialize(value)
+	if err != nil {
+		return nil, err
+	}
+	return s.Send("ZADD", z.name, score, data), nil
+}
+
+// SendRemove 将移除元素的命令排入管道或事务
+func (z *RedisZSet) SendRemove(s Sender, values ...interface{}) (*Result, error) {
+	args := make([]interface{}, 0, len(values)+1)
+	args = append(args, z.name)
+	for _, value := range values {
+		data, err := z.redis.Serialize(value)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, data)
+	}
+	return s.Send("ZREM", args...), nil
+}
+
+// SendIncrementScore 将增加元素分数的命令排入管道或事务
+func (z *RedisZSet) SendIncrementScore(s Sender, value interface{}, delta float64) (*Result, error) {
+	data, err := z.redis.Serialize(value)
+	if err != nil {
+		return nil, err
+	}
+	return s.Send("ZINCRBY", z.name, delta, data), nil
+}
+
 // Iterator 获取迭代器（按分数过滤）
 func (z *RedisZSet) Iterator(batchSize int) <-chan struct {
 	Value interface{}
@@ -222,6 +268,18 @@ func (z *RedisZSet) Iterator(batchSize int) <-chan struct {
 
 // === RedisTypeZSet 类型化方法 ===
 
+// WithContext 返回绑定了上下文的有序集合视图
+func (tz *RedisTypeZSet[T]) WithContext(ctx context.Context) *RedisTypeZSet[T] {
+	return &RedisTypeZSet[T]{
+		zset: tz.zset.WithContext(ctx),
+	}
+}
+
+// Name 获取有序集合的 Redis 键名
+func (tz *RedisTypeZSet[T]) Name() string {
+	return tz.zset.Name()
+}
+
 // Add 添加元素
 func (tz *RedisTypeZSet[T]) Add(value T, score float64) error {
 	return tz.zset.Add(value, score)
@@ -321,6 +379,25 @@ func (tz *RedisTypeZSet[T]) RemoveRangeByScore(min, max float64) error {
 	return tz.zset.RemoveRangeByScore(min, max)
 }
 
+// SendAdd 将添加元素的命令排入管道或事务
+func (tz *RedisTypeZSet[T]) SendAdd(s Sender, value T, score float64) (*Result, error) {
+	return tz.zset.SendAdd(s, value, score)
+}
+
+// SendRemove 将移除元素的命令排入管道或事务
+func (tz *RedisTypeZSet[T]) SendRemove(s Sender, values ...T) (*Result, error) {
+	interfaces := make([]interface{}, len(values))
+	for i, v := range values {
+		interfaces[i] = v
+	}
+	return tz.zset.SendRemove(s, interfaces...)
+}
+
+// SendIncrementScore 将增加元素分数的命令排入管道或事务
+func (tz *RedisTypeZSet[T]) SendIncrementScore(s Sender, value T, delta float64) (*Result, error) {
+	return tz.zset.SendIncrementScore(s, value, delta)
+}
+
 // Iterator 获取迭代器
 func (tz *RedisTypeZSet[T]) Iterator(batchSize int) <-chan ZSetItem[T] {
 	ch := make(chan ZSetItem[T])